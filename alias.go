@@ -0,0 +1,59 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// alias replaces the subcommand sub of the top component c with the
+// arguments of its alias, if one is defined, and reports whether it did.
+func (c *Component) alias(sub string) (bool, error) {
+	expansion, exists := c.aliases[sub]
+	if c.parent != nil || !exists {
+		return false, nil
+	}
+
+	if c.expanded[sub] {
+		return false, parsef(ErrUnknownCommand, "alias %q expands to itself", sub)
+	}
+	c.expanded[sub] = true
+
+	words, err := fields(expansion)
+	if err != nil {
+		return false, parsef(ErrBadValue, "alias %q is not valid: %v", sub, err)
+	}
+
+	c.logger.Debug("babycli: expanded alias", "name", sub, "arguments", words)
+	c.args.insert(words)
+	return true, nil
+}
+
+// ReadAliases reads aliases for Configuration.Aliases from r, one per line in
+// the form "name = arguments". Blank lines and lines starting with # are
+// ignored.
+func ReadAliases(r io.Reader) (map[string]string, error) {
+	aliases := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, expansion, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("babycli: line %d is not an alias: %q", n, line)
+		}
+		aliases[name] = strings.TrimSpace(expansion)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("babycli: unable to read aliases: %w", err)
+	}
+	return aliases, nil
+}
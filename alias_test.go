@@ -0,0 +1,107 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestParse_aliases(t *testing.T) {
+	t.Parallel()
+
+	aliases := map[string]string{
+		"dd":     "deploy --env dev",
+		"dp":     "dd --env 'prod'",
+		"config": "deploy",
+		"loop":   "again",
+		"again":  "loop",
+		"broken": `deploy "`,
+	}
+
+	cases := []struct {
+		name   string
+		args   []string
+		expEnv string
+		expCmd string
+		expErr string
+	}{
+		{
+			name:   "expands",
+			args:   []string{"dd", "--wait", "1s"},
+			expCmd: "deploy",
+			expEnv: "dev",
+		},
+		{
+			name:   "after globals",
+			args:   []string{"-d", "dd"},
+			expCmd: "deploy",
+			expEnv: "dev",
+		},
+		{
+			name:   "nested",
+			args:   []string{"dp"},
+			expCmd: "deploy",
+			expEnv: "prod",
+		},
+		{
+			name:   "command wins",
+			args:   []string{"config", "get"},
+			expCmd: "get",
+		},
+		{
+			name:   "recursive",
+			args:   []string{"loop"},
+			expErr: `babycli: alias "loop" expands to itself`,
+		},
+		{
+			name:   "invalid",
+			args:   []string{"broken"},
+			expErr: `babycli: alias "broken" is not valid: unterminated quote`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := parseConfig(tc.args)
+			config.Aliases = aliases
+			config.Duplicates = DuplicatesLastWins
+			leaf, err := Parse(config)
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expCmd, leaf.Name)
+			if tc.expEnv != "" {
+				must.Eq(t, tc.expEnv, leaf.GetString("env"))
+			}
+		})
+	}
+}
+
+func TestReadAliases(t *testing.T) {
+	t.Parallel()
+
+	aliases, err := ReadAliases(strings.NewReader(`
+# shortcuts
+co = checkout --force
+st=status -s
+
+lg = log --format="%h %s"
+`))
+	must.NoError(t, err)
+	must.MapEq(t, map[string]string{
+		"co": "checkout --force",
+		"st": "status -s",
+		"lg": `log --format="%h %s"`,
+	}, aliases)
+
+	_, err = ReadAliases(strings.NewReader("co = checkout\nnot an alias\n"))
+	must.EqError(t, err, `babycli: line 2 is not an alias: "not an alias"`)
+}
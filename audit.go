@@ -0,0 +1,97 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// auditRecord is the line written to the audit log for each run.
+type auditRecord struct {
+	Time  time.Time           `json:"time"`
+	Path  []string            `json:"path"`
+	Flags map[string][]string `json:"flags"`
+	Code  Code                `json:"code"`
+}
+
+// audit writes the record of the run started at start to the audit log, as
+// a line of JSON. The values of secret and bytes flags are redacted.
+func (r *Runnable) audit(start time.Time, code Code) {
+	if r.auditLog == nil {
+		return
+	}
+
+	record := &auditRecord{
+		Time:  start.UTC(),
+		Path:  r.resolved().path(),
+		Flags: r.root.vals.sanitized(),
+		Code:  code,
+	}
+
+	b, err := json.Marshal(record)
+	if err == nil {
+		_, err = r.auditLog.Write(append(b, '\n'))
+	}
+	if err != nil {
+		r.root.logger.Error("babycli: unable to write audit log", "error", err)
+	}
+}
+
+// sanitized returns the values of each flag given as text, with the values of
+// secrets and bytes flags, such as keys and nonces, masked.
+func (v *values) sanitized() map[string][]string {
+	flags := make(map[string][]string)
+	for name, ss := range v.strings {
+		flags[name] = append(flags[name], ss...)
+	}
+	for name, is := range v.ints {
+		for _, i := range is {
+			flags[name] = append(flags[name], strconv.Itoa(i))
+		}
+	}
+	for name, bs := range v.bools {
+		for _, b := range bs {
+			flags[name] = append(flags[name], strconv.FormatBool(b))
+		}
+	}
+	for name, ds := range v.durations {
+		for _, d := range ds {
+			flags[name] = append(flags[name], d.String())
+		}
+	}
+	for name, ts := range v.dates {
+		for _, t := range ts {
+			flags[name] = append(flags[name], t.Format(time.DateOnly))
+		}
+	}
+	for name, is := range v.bigs {
+		for _, i := range is {
+			flags[name] = append(flags[name], i.String())
+		}
+	}
+	for name, bs := range v.bytes {
+		for range bs {
+			flags[name] = append(flags[name], mask)
+		}
+	}
+	for name, ps := range v.files {
+		flags[name] = append(flags[name], ps...)
+	}
+	for name, ps := range v.dirs {
+		flags[name] = append(flags[name], ps...)
+	}
+	for name, gs := range v.globs {
+		for _, ps := range gs {
+			flags[name] = append(flags[name], ps...)
+		}
+	}
+	for name, ss := range v.secrets {
+		for _, s := range ss {
+			flags[name] = append(flags[name], s.String())
+		}
+	}
+	return flags
+}
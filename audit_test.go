@@ -0,0 +1,86 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRun_auditLog(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expPath  []string
+		expFlags map[string][]string
+		expCode  Code
+	}{
+		{
+			name:    "values",
+			args:    []string{"-d", "deploy", "--wait=1m30s", "-e", "dev", "--replicas", "1", "--replicas", "2", "--token", "hunter2"},
+			expPath: []string{"tool", "deploy"},
+			expFlags: map[string][]string{
+				"debug":    {"true"},
+				"env":      {"dev"},
+				"wait":     {"1m30s"},
+				"replicas": {"1", "2"},
+				"token":    {"********"},
+			},
+			expCode: Success,
+		},
+		{
+			name:     "invalid",
+			args:     []string{"deploy", "--env", "staging"},
+			expPath:  []string{"tool", "deploy"},
+			expFlags: map[string][]string{},
+			expCode:  Failure,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := new(strings.Builder)
+			config := parseConfig(tc.args)
+			config.Output = io.Discard
+			config.AuditLog = log
+
+			before := time.Now()
+			code := New(config).Run()
+			must.Eq(t, tc.expCode, code)
+
+			must.StrHasSuffix(t, "\n", log.String())
+			var record auditRecord
+			must.NoError(t, json.Unmarshal([]byte(log.String()), &record))
+			must.Eq(t, tc.expPath, record.Path)
+			must.MapEq(t, tc.expFlags, record.Flags)
+			must.Eq(t, tc.expCode, record.Code)
+			must.False(t, record.Time.Before(before.Truncate(time.Second)))
+			must.False(t, strings.Contains(log.String(), "hunter2"))
+		})
+	}
+}
+
+func TestValues_sanitized(t *testing.T) {
+	t.Parallel()
+
+	v := &values{
+		strings: map[string][]string{"env": {"dev"}},
+		bytes:   map[string][][]byte{"key": {[]byte("k1"), []byte("k2")}},
+		secrets: map[string][]secret{"token": {"hunter2"}},
+	}
+	must.MapEq(t, map[string][]string{
+		"env":   {"dev"},
+		"key":   {mask, mask},
+		"token": {mask},
+	}, v.sanitized())
+}
@@ -0,0 +1,65 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package babyclitest provides utilities for testing programs built with
+// babycli, running a Configuration with injected arguments, environment, and
+// streams.
+package babyclitest
+
+import (
+	"strings"
+
+	"noxide.lol/go/babycli"
+)
+
+// Input is what a program is run with. The environment contains only the
+// variables of Env.
+type Input struct {
+	Args  []string
+	Env   map[string]string
+	Stdin string
+}
+
+// Result is the outcome of running a program.
+type Result struct {
+	Code   babycli.Code
+	Stdout string
+	Stderr string
+
+	// Leaf is the component the arguments resolved to, or nil if they could
+	// not be parsed.
+	Leaf *babycli.Component
+
+	// Err is the error from parsing the arguments, if any.
+	Err error
+}
+
+// Run runs a copy of config with in, capturing the output streams. The
+// Arguments, streams, and Getenv of config are replaced, and the rest of its
+// settings are kept.
+func Run(config *babycli.Configuration, in Input) *Result {
+	stdout := new(strings.Builder)
+	stderr := new(strings.Builder)
+
+	c := *config
+	c.Arguments = in.Args
+	c.Output = nil
+	c.Stdout = stdout
+	c.Stderr = stderr
+	c.Stdin = strings.NewReader(in.Stdin)
+	c.Getenv = func(key string) string {
+		return in.Env[key]
+	}
+
+	r := babycli.New(&c)
+	code := r.Run()
+	leaf, err := r.Parse()
+
+	return &Result{
+		Code:   code,
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Leaf:   leaf,
+		Err:    err,
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babyclitest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test/must"
+	"noxide.lol/go/babycli"
+)
+
+func config() *babycli.Configuration {
+	return &babycli.Configuration{
+		Top: &babycli.Component{
+			Name: "tool",
+			Components: babycli.Components{
+				{
+					Name: "greet",
+					Flags: babycli.Flags{
+						{Type: babycli.StringFlag, Long: "name"},
+					},
+					Function: func(c *babycli.Component) babycli.Code {
+						c.Printf("hello %s from %s\n", c.GetString("name"), c.Getenv("REGION"))
+						return babycli.Success
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	result := Run(config(), Input{
+		Args: []string{"greet", "--name", "bob"},
+		Env:  map[string]string{"REGION": "us-east-1"},
+	})
+
+	must.Zero(t, result.Code)
+	must.Eq(t, "hello bob from us-east-1\n", result.Stdout)
+	must.Eq(t, "", result.Stderr)
+	must.Eq(t, "greet", result.Leaf.Name)
+	must.NoError(t, result.Err)
+}
+
+func TestRun_parse_error(t *testing.T) {
+	t.Parallel()
+
+	result := Run(config(), Input{
+		Args: []string{"greet", "--nope"},
+	})
+
+	must.One(t, result.Code)
+	must.Eq(t, "", result.Stdout)
+	must.StrContains(t, result.Stderr, `flag "nope" is not defined`)
+	must.Nil(t, result.Leaf)
+	must.True(t, errors.Is(result.Err, babycli.ErrUnknownFlag))
+}
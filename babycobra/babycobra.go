@@ -0,0 +1,228 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package babycobra adapts commands written with cobra and pflag to babycli
+// and back, so a large program can be migrated one subcommand at a time.
+package babycobra
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"noxide.lol/go/babycli"
+)
+
+// FromFlagSet converts the flags defined in fs. Slice and array flags repeat,
+// count flags become repeating boolean flags, and flags of types other than
+// bool, int, and time.Duration become string flags. A flag annotated for
+// filename completion by cobra is annotated with "file-completion". Use Apply
+// to set the values of fs from those parsed by babycli.
+func FromFlagSet(fs *pflag.FlagSet) babycli.Flags {
+	var flags babycli.Flags
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, fromFlag(f))
+	})
+	return flags
+}
+
+func fromFlag(f *pflag.Flag) *babycli.Flag {
+	converted := &babycli.Flag{
+		Type:       babycli.StringFlag,
+		Short:      f.Shorthand,
+		Help:       f.Usage,
+		Hidden:     f.Hidden,
+		Deprecated: f.Deprecated,
+	}
+	if len(f.Name) == 1 && f.Shorthand == "" {
+		converted.Short = f.Name
+	} else {
+		converted.Long = f.Name
+	}
+	if _, exists := f.Annotations[cobra.BashCompFilenameExt]; exists {
+		converted.Annotations = map[string]string{"file-completion": "true"}
+	}
+
+	var value any
+	switch f.Value.Type() {
+	case "bool":
+		converted.Type = babycli.BooleanFlag
+		value, _ = strconv.ParseBool(f.DefValue)
+	case "int":
+		converted.Type = babycli.IntFlag
+		value, _ = strconv.Atoi(f.DefValue)
+	case "duration":
+		converted.Type = babycli.DurationFlag
+		value, _ = time.ParseDuration(f.DefValue)
+	case "count":
+		converted.Type = babycli.BooleanFlag
+		converted.Repeats = true
+	case "intSlice":
+		converted.Type = babycli.IntFlag
+		converted.Repeats = true
+	case "stringSlice", "stringArray":
+		converted.Repeats = true
+	default:
+		value = f.DefValue
+	}
+	if value != nil {
+		converted.Default = &babycli.Default{Value: value, Show: !zero(f.DefValue)}
+	}
+	return converted
+}
+
+func zero(s string) bool {
+	switch s {
+	case "", "0", "false", "0s", "[]":
+		return true
+	}
+	return false
+}
+
+// Apply sets each flag of fs defined on c to the values given to c on the
+// command line, in order, leaving the defaults of fs for flags not given.
+func Apply(c *babycli.Component, fs *pflag.FlagSet) error {
+	forward := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.VisitAll(func(f *pflag.Flag) {
+		forward.Var(&setter{fs: fs, flag: f}, f.Name, f.Usage)
+	})
+	return c.Apply(forward)
+}
+
+// setter sets a pflag flag through the flag package.
+type setter struct {
+	fs   *pflag.FlagSet
+	flag *pflag.Flag
+}
+
+func (s *setter) String() string {
+	if s.flag == nil {
+		return ""
+	}
+	return s.flag.Value.String()
+}
+
+func (s *setter) Set(value string) error {
+	if s.flag.Value.Type() == "count" {
+		if b, err := strconv.ParseBool(value); err != nil || !b {
+			return err
+		}
+		value = "+1"
+	}
+	return s.fs.Set(s.flag.Name, value)
+}
+
+// FromCommand converts cmd and its subcommands into a Component. Running a
+// converted command sets the flags of cmd with Apply, checks its Args, and
+// calls its PreRun, Run, and PostRun functions, or their E variants. The
+// persistent hooks of cobra are not called.
+func FromCommand(cmd *cobra.Command) *babycli.Component {
+	c := &babycli.Component{
+		Name:        cmd.Name(),
+		Help:        cmd.Short,
+		Description: cmd.Long,
+		Hidden:      cmd.Hidden,
+		Deprecated:  cmd.Deprecated,
+		Flags:       FromFlagSet(cmd.LocalNonPersistentFlags()),
+		Persistent:  FromFlagSet(cmd.PersistentFlags()),
+	}
+	if cmd.Runnable() {
+		c.FunctionE = func(c *babycli.Component) error {
+			return run(cmd, c)
+		}
+		c.RunWithoutSubcommand = cmd.HasSubCommands()
+	}
+	for _, sub := range cmd.Commands() {
+		c.Components = append(c.Components, FromCommand(sub))
+	}
+	return c
+}
+
+func run(cmd *cobra.Command, c *babycli.Component) error {
+	if err := Apply(c, cmd.LocalFlags()); err != nil {
+		return err
+	}
+	if err := Apply(c, cmd.InheritedFlags()); err != nil {
+		return err
+	}
+
+	args := c.Arguments()
+	if cmd.Args != nil {
+		if err := cmd.Args(cmd, args); err != nil {
+			return babycli.UsageError(err)
+		}
+	}
+
+	cmd.SetContext(c.Context())
+	cmd.SetIn(c.Stdin())
+	cmd.SetOut(c.Stdout())
+	cmd.SetErr(c.Stderr())
+
+	steps := []struct {
+		fn  func(*cobra.Command, []string)
+		fnE func(*cobra.Command, []string) error
+	}{
+		{cmd.PreRun, cmd.PreRunE},
+		{cmd.Run, cmd.RunE},
+		{cmd.PostRun, cmd.PostRunE},
+	}
+	for _, step := range steps {
+		switch {
+		case step.fnE != nil:
+			if err := step.fnE(cmd, args); err != nil {
+				return err
+			}
+		case step.fn != nil:
+			step.fn(cmd, args)
+		}
+	}
+	return nil
+}
+
+// ExitError is returned by a command made with ToCommand when its program
+// exits with a code other than Success.
+type ExitError struct {
+	Code babycli.Code
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("babycobra: exited with code %d", e.Code)
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// ToCommand converts the program of config into a cobra.Command, which runs
+// it with the arguments given after the name of the command. The command
+// leaves flag parsing and help to babycli, using the context and streams of
+// cobra, and does not print the errors babycli already reported.
+func ToCommand(config *babycli.Configuration) *cobra.Command {
+	top := config.Top
+	return &cobra.Command{
+		Use:                top.Name,
+		Short:              top.Help,
+		Long:               top.Description,
+		Hidden:             top.Hidden,
+		Deprecated:         top.Deprecated,
+		DisableFlagParsing: true,
+		SilenceErrors:      true,
+		SilenceUsage:       true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := *config
+			c.Arguments = args
+			c.Context = cmd.Context()
+			c.Stdin = cmd.InOrStdin()
+			c.Stdout = cmd.OutOrStdout()
+			c.Stderr = cmd.ErrOrStderr()
+			c.Output = cmd.ErrOrStderr()
+			if code := babycli.New(&c).Run(); code != babycli.Success {
+				return &ExitError{Code: code}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycobra
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"noxide.lol/go/babycli"
+)
+
+func TestFromFlagSet(t *testing.T) {
+	t.Parallel()
+
+	fs := pflag.NewFlagSet("deploy", pflag.ContinueOnError)
+	fs.StringP("region", "r", "us", "region to use")
+	fs.Int("replicas", 0, "number of replicas")
+	fs.Duration("wait", time.Minute, "time to wait")
+	fs.CountP("verbose", "v", "more output")
+	fs.StringSlice("tag", nil, "tags to add")
+	fs.String("manifest", "", "manifest file")
+	must.NoError(t, fs.SetAnnotation("manifest", cobra.BashCompFilenameExt, []string{"yaml"}))
+	fs.Bool("legacy", false, "use the old path")
+	must.NoError(t, fs.MarkDeprecated("legacy", "use --region"))
+
+	flags := FromFlagSet(fs)
+	must.Len(t, 7, flags)
+
+	region := flags.Get("region")
+	must.Eq(t, babycli.StringFlag, region.Type)
+	must.Eq(t, "r", region.Short)
+	must.Eq(t, &babycli.Default{Value: "us", Show: true}, region.Default)
+
+	must.Eq(t, babycli.IntFlag, flags.Get("replicas").Type)
+	must.Eq(t, &babycli.Default{Value: 0}, flags.Get("replicas").Default)
+	must.Eq(t, &babycli.Default{Value: time.Minute, Show: true}, flags.Get("wait").Default)
+
+	verbose := flags.Get("verbose")
+	must.Eq(t, babycli.BooleanFlag, verbose.Type)
+	must.True(t, verbose.Repeats)
+	must.Nil(t, verbose.Default)
+
+	must.True(t, flags.Get("tag").Repeats)
+	must.Eq(t, map[string]string{"file-completion": "true"}, flags.Get("manifest").Annotations)
+	must.Eq(t, "use --region", flags.Get("legacy").Deprecated)
+}
+
+func TestFromCommand(t *testing.T) {
+	t.Parallel()
+
+	var (
+		region string
+		wait   time.Duration
+		tags   []string
+		level  int
+		got    []string
+		steps  []string
+	)
+
+	root := &cobra.Command{Use: "tool", Short: "a tool"}
+	root.PersistentFlags().StringVar(&region, "region", "us", "region to use")
+
+	deploy := &cobra.Command{
+		Use:   "deploy [app]",
+		Short: "deploy an app",
+		Args:  cobra.MaximumNArgs(1),
+		PreRun: func(*cobra.Command, []string) {
+			steps = append(steps, "pre")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps = append(steps, "run")
+			got = args
+			cmd.Print("deployed")
+			return nil
+		},
+		PostRunE: func(*cobra.Command, []string) error {
+			steps = append(steps, "post")
+			return nil
+		},
+	}
+	deploy.Flags().DurationVar(&wait, "wait", time.Minute, "time to wait")
+	deploy.Flags().StringSliceVar(&tags, "tag", nil, "tags to add")
+	deploy.Flags().CountVarP(&level, "verbose", "v", "more output")
+	root.AddCommand(deploy)
+
+	top := FromCommand(root)
+	must.Eq(t, "tool", top.Name)
+	must.Nil(t, top.FunctionE)
+	must.Eq(t, "deploy an app", top.Find("deploy").Help)
+
+	stdout := new(strings.Builder)
+	code := babycli.New(&babycli.Configuration{
+		Top:       top,
+		Stdout:    stdout,
+		Arguments: []string{"deploy", "--region", "eu", "--tag", "a,b", "--tag", "c", "-v", "-v", "--wait", "5m", "app"},
+	}).Run()
+	must.Eq(t, babycli.Success, code)
+	must.Eq(t, "eu", region)
+	must.Eq(t, 5*time.Minute, wait)
+	must.Eq(t, []string{"a", "b", "c"}, tags)
+	must.Eq(t, 2, level)
+	must.Eq(t, []string{"app"}, got)
+	must.Eq(t, []string{"pre", "run", "post"}, steps)
+	must.Eq(t, "deployed", stdout.String())
+
+	output := new(strings.Builder)
+	code = babycli.New(&babycli.Configuration{
+		Top:       FromCommand(root),
+		Output:    output,
+		Arguments: []string{"deploy", "one", "two"},
+	}).Run()
+	must.Eq(t, babycli.Failure, code)
+	must.StrContains(t, output.String(), "accepts at most 1 arg(s), received 2")
+}
+
+func TestToCommand(t *testing.T) {
+	t.Parallel()
+
+	var name string
+	config := &babycli.Configuration{
+		Top: &babycli.Component{
+			Name: "greet",
+			Help: "greet someone",
+			Flags: babycli.Flags{
+				{Type: babycli.StringFlag, Long: "name", Require: true},
+			},
+			Function: func(c *babycli.Component) babycli.Code {
+				name = c.GetString("name")
+				c.Printf("hello %s", name)
+				return babycli.Success
+			},
+		},
+	}
+
+	root := &cobra.Command{Use: "tool"}
+	root.AddCommand(ToCommand(config))
+
+	stdout := new(strings.Builder)
+	root.SetOut(stdout)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"greet", "--name", "bob"})
+	must.NoError(t, root.Execute())
+	must.Eq(t, "bob", name)
+	must.Eq(t, "hello bob", stdout.String())
+
+	root.SetArgs([]string{"greet"})
+	err := root.Execute()
+	var exit *ExitError
+	must.True(t, errors.As(err, &exit))
+	must.Eq(t, babycli.Failure, exit.ExitCode())
+}
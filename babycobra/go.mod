@@ -0,0 +1,15 @@
+module noxide.lol/go/babycli/babycobra
+
+go 1.23
+
+require (
+	github.com/shoenig/test v1.8.2
+	github.com/spf13/cobra v1.8.1
+	github.com/spf13/pflag v1.0.5
+	noxide.lol/go/babycli v0.0.0-20261016201909-d22e52e002fe
+)
+
+require (
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+)
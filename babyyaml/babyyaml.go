@@ -0,0 +1,29 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package babyyaml loads babycli command trees from YAML specs, so babycli
+// itself does not depend on a YAML library.
+package babyyaml
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+	"noxide.lol/go/babycli"
+)
+
+// Load reads a YAML Spec, with the same fields as the JSON read by
+// babycli.Load, and returns a Configuration with its component tree, version,
+// and global flags. Functions are not part of a Spec; attach them to the
+// components found with Find before calling babycli.New.
+func Load(r io.Reader) (*babycli.Configuration, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	s := new(babycli.Spec)
+	if err := decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("babycli: unable to decode spec: %w", err)
+	}
+	return s.Configuration()
+}
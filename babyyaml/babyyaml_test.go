@@ -0,0 +1,103 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babyyaml
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"noxide.lol/go/babycli"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	spec := `
+name: tool
+version: v1.2.3
+globals:
+  - long: timeout
+    type: duration
+    default: 30s
+  - long: help
+    short: h
+    type: boolean
+commands:
+  - name: deploy
+    examples:
+      - help: deploy five replicas
+        command: tool deploy -r 5
+    flags:
+      - long: replicas
+        short: r
+        type: integer
+        default: 3
+        show: true
+      - long: since
+        type: date
+        default: 2024-01-02
+      - long: format
+        type: string
+        choices: [json, yaml]
+`
+
+	config, err := Load(strings.NewReader(spec))
+	must.NoError(t, err)
+	must.Eq(t, "v1.2.3", config.Version)
+	must.Len(t, 1, config.Globals)
+	must.Eq(t, "tool deploy -r 5", config.Top.Find("deploy").Examples[0].Command)
+
+	var replicas int
+	var timeout time.Duration
+	var since time.Time
+	config.Top.Find("deploy").Function = func(c *babycli.Component) babycli.Code {
+		replicas = c.GetInt("replicas")
+		timeout = c.GetDuration("timeout")
+		since = c.GetDate("since")
+		return babycli.Success
+	}
+	config.Arguments = []string{"deploy", "-r", "5"}
+	config.Output = new(strings.Builder)
+
+	must.Zero(t, babycli.New(config).Run())
+	must.Eq(t, 5, replicas)
+	must.Eq(t, 30*time.Second, timeout)
+	must.Eq(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), since)
+}
+
+func TestLoad_errors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		spec string
+		exp  string
+	}{
+		{
+			name: "unknown field",
+			spec: "name: tool\nsubcommands: []\n",
+			exp:  "babycli: unable to decode spec: yaml: unmarshal errors:\n  line 2: field subcommands not found in type babycli.Spec",
+		},
+		{
+			name: "unknown type",
+			spec: "name: tool\nflags:\n  - long: size\n    type: huge\n",
+			exp:  `babycli: flag "size" has unknown type "huge"`,
+		},
+		{
+			name: "invalid default",
+			spec: "name: tool\nflags:\n  - long: size\n    type: integer\n    default: big\n",
+			exp:  `babycli: default big of integer flag "size" is not valid`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Load(strings.NewReader(tc.spec))
+			must.EqError(t, err, tc.exp)
+		})
+	}
+}
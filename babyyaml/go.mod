@@ -0,0 +1,11 @@
+module noxide.lol/go/babycli/babyyaml
+
+go 1.23
+
+require (
+	github.com/shoenig/test v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
+	noxide.lol/go/babycli v0.0.0-20261016201909-d22e52e002fe
+)
+
+require github.com/google/go-cmp v0.6.0 // indirect
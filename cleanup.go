@@ -0,0 +1,40 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import "sync"
+
+type cleanups struct {
+	lock  sync.Mutex
+	funcs []func()
+}
+
+// OnCleanup registers f to be called after the Function of the command
+// returns or panics, or once the ShutdownTimeout has passed after a signal if
+// it is still running. Cleanups are called in the reverse order of
+// registration, each at most once.
+func (c *Component) OnCleanup(f func()) {
+	c.cleanups.lock.Lock()
+	defer c.cleanups.lock.Unlock()
+	c.cleanups.funcs = append(c.cleanups.funcs, f)
+}
+
+func (cs *cleanups) run() {
+	for f := cs.pop(); f != nil; f = cs.pop() {
+		f()
+	}
+}
+
+// pop removes and returns the cleanup registered last, or nil if none is left.
+func (cs *cleanups) pop() func() {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	if len(cs.funcs) == 0 {
+		return nil
+	}
+	last := len(cs.funcs) - 1
+	f := cs.funcs[last]
+	cs.funcs = cs.funcs[:last]
+	return f
+}
@@ -0,0 +1,111 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestComponent_OnCleanup(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		panics  bool
+		expCode Code
+	}{
+		{name: "returns", panics: false, expCode: Success},
+		{name: "panics", panics: true, expCode: Failure},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			config := &Configuration{
+				Arguments: []string{"child"},
+				Output:    new(strings.Builder),
+				Top: &Component{
+					Components: Components{
+						{
+							Name: "child",
+							Function: func(c *Component) Code {
+								c.OnCleanup(func() { calls = append(calls, "first") })
+								c.OnCleanup(func() { calls = append(calls, "second") })
+								if tc.panics {
+									panicf("boom")
+								}
+								return Success
+							},
+						},
+					},
+				},
+			}
+			result := New(config).Run()
+			must.Eq(t, tc.expCode, result)
+			must.Eq(t, []string{"second", "first"}, calls)
+		})
+	}
+}
+
+func TestConfiguration_Signals(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sending signals")
+	}
+
+	var calls []string
+	config := &Configuration{
+		Signals: []os.Signal{os.Interrupt},
+		Top: &Component{
+			Function: func(c *Component) Code {
+				c.OnCleanup(func() { calls = append(calls, "cleanup") })
+				p, err := os.FindProcess(os.Getpid())
+				must.NoError(t, err)
+				must.NoError(t, p.Signal(os.Interrupt))
+				<-c.Context().Done()
+				calls = append(calls, "cancelled")
+				return Failure
+			},
+		},
+	}
+	must.Eq(t, Failure, New(config).Run())
+	must.Eq(t, []string{"cancelled", "cleanup"}, calls)
+}
+
+func TestConfiguration_ShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sending signals")
+	}
+
+	var calls []string
+	exited := make(chan Code)
+	config := &Configuration{
+		Signals:         []os.Signal{os.Interrupt},
+		ShutdownTimeout: 10 * time.Millisecond,
+		Top: &Component{
+			Function: func(c *Component) Code {
+				c.OnCleanup(func() { calls = append(calls, "cleanup") })
+				p, err := os.FindProcess(os.Getpid())
+				must.NoError(t, err)
+				must.NoError(t, p.Signal(os.Interrupt))
+				// Blocked on something which ignores the context.
+				must.Eq(t, Failure, <-exited)
+				return Success
+			},
+		},
+	}
+	r := New(config)
+	r.exit = func(code Code) { exited <- code }
+	must.Eq(t, Success, r.Run())
+	must.Eq(t, []string{"cleanup"}, calls)
+}
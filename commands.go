@@ -5,22 +5,42 @@ package babycli
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
-
-	"noxide.lol/go/stacks"
 )
 
 type Func func(*Component) Code
 
+// FuncE is an alternative to Func for commands that fail with an error. The
+// error is reported through the ErrorHandler and the exit code is Failure,
+// unless the error provides its own through an ExitCode() int method.
+type FuncE func(*Component) error
+
 type values struct {
 	strings   map[string][]string
 	ints      map[string][]int
 	bools     map[string][]bool
 	durations map[string][]time.Duration
+	secrets   map[string][]secret
+	dates     map[string][]time.Time
+	bigs      map[string][]*big.Int
+	bytes     map[string][][]byte
+	files     map[string][]string
+	dirs      map[string][]string
+	globs     map[string][][]string
+
+	// given counts the times each flag, by identity, was given on the command
+	// line, however many values each time carried.
+	given map[string]int
 }
 
 func (v *values) stringCount(flag string) int {
@@ -39,6 +59,93 @@ func (v *values) durationCount(flag string) int {
 	return len(v.durations[flag])
 }
 
+func (v *values) secretCount(flag string) int {
+	return len(v.secrets[flag])
+}
+
+func (v *values) dateCount(flag string) int {
+	return len(v.dates[flag])
+}
+
+func (v *values) bigCount(flag string) int {
+	return len(v.bigs[flag])
+}
+
+func (v *values) bytesCount(flag string) int {
+	return len(v.bytes[flag])
+}
+
+func (v *values) fileCount(flag string) int {
+	return len(v.files[flag])
+}
+
+func (v *values) dirCount(flag string) int {
+	return len(v.dirs[flag])
+}
+
+func (v *values) globCount(flag string) int {
+	return len(v.globs[flag])
+}
+
+// count returns the number of values given for f.
+func (v *values) count(f *Flag) int {
+	identity := f.Identity()
+	switch f.Type {
+	case StringFlag:
+		return v.stringCount(identity)
+	case IntFlag:
+		return v.intCount(identity)
+	case BooleanFlag:
+		return v.boolCount(identity)
+	case DurationFlag:
+		return v.durationCount(identity)
+	case SecretFlag:
+		return v.secretCount(identity)
+	case DateFlag:
+		return v.dateCount(identity)
+	case BigIntFlag:
+		return v.bigCount(identity)
+	case BytesLiteralFlag:
+		return v.bytesCount(identity)
+	case OpenFileFlag:
+		return v.fileCount(identity)
+	case DirFlag:
+		return v.dirCount(identity)
+	case GlobFlag:
+		return v.globCount(identity)
+	}
+	return 0
+}
+
+// reset discards the values given for f.
+func (v *values) reset(f *Flag) {
+	identity := f.Identity()
+	switch f.Type {
+	case StringFlag:
+		delete(v.strings, identity)
+	case IntFlag:
+		delete(v.ints, identity)
+	case BooleanFlag:
+		delete(v.bools, identity)
+	case DurationFlag:
+		delete(v.durations, identity)
+	case SecretFlag:
+		delete(v.secrets, identity)
+	case DateFlag:
+		delete(v.dates, identity)
+	case BigIntFlag:
+		delete(v.bigs, identity)
+	case BytesLiteralFlag:
+		delete(v.bytes, identity)
+	case OpenFileFlag:
+		delete(v.files, identity)
+	case DirFlag:
+		delete(v.dirs, identity)
+	case GlobFlag:
+		delete(v.globs, identity)
+	}
+}
+
 func (v *values) helpSet() bool {
 	for k, bs := range v.bools {
 		if k == "help" || k == "h" {
@@ -60,6 +167,30 @@ func (cs Components) Contains(name string) bool {
 	})
 }
 
+// Merge adds the components of other to cs, so a tree can be assembled from
+// the subtrees of several packages. If any name of other is already in cs, or
+// is in other more than once, nothing is added and the collisions are
+// returned as an error.
+func (cs *Components) Merge(other Components) error {
+	var errs []error
+	for i, c := range other {
+		if cs.Contains(c.Name) || other[:i].Contains(c.Name) {
+			errs = append(errs, fmt.Errorf("babycli: component %q is already defined", c.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	*cs = append(*cs, other...)
+	return nil
+}
+
+func (cs Components) visible() Components {
+	return slices.DeleteFunc(slices.Clone(cs), func(c *Component) bool {
+		return c.Hidden || c.Deprecated != "" || !c.enabled()
+	})
+}
+
 func (cs Components) Get(name string) *Component {
 	for _, c := range cs {
 		if c.Name == name {
@@ -77,23 +208,92 @@ type Component struct {
 
 	Description string
 
-	Components Components
+	// Examples are listed in help, and checked by VerifyExamples.
+	Examples []Example
 
-	Function Func
+	Category string
 
-	Flags Flags
+	// Hidden components can be run but are left out of help and documentation.
+	Hidden bool
+
+	// Annotations carry metadata for tools built around the command tree,
+	// such as release automation or docs pipelines, through Walk and Spec,
+	// and are listed in the Markdown and man pages. Completion offers file
+	// names for the arguments of a component annotated with
+	// "file-completion" set to "true".
+	Annotations map[string]string
+
+	// ShellAlias names a shell alias running the component, such as "mtd"
+	// for "mytool deploy", written by GenShellAliases.
+	ShellAlias string
 
-	args stacks.Stack[string]
+	// Deprecated components can be run with a warning of this message, such
+	// as naming their replacement, but are left out of help and documentation.
+	Deprecated string
 
-	flat []string
+	// Enabled reports whether the component is available, such as behind a
+	// feature flag or on some platforms only. Components which are not are
+	// left out of help and fail with Unavailable when run.
+	Enabled func() bool
 
-	vals *values
+	// Unavailable is the message reported when the component is run while
+	// not Enabled, instead of the default.
+	Unavailable string
 
-	globals Flags
+	Components Components
+
+	Default string
 
-	version string
+	Function Func
 
-	context context.Context
+	FunctionE FuncE
+
+	// Before and After are hooks run around the Function of this component
+	// and of every descendant, root-to-leaf for Before and leaf-to-root for After.
+	Before Func
+	After  Func
+
+	Flags Flags
+
+	// Persistent flags are accepted by this component and by all of its
+	// descendants.
+	Persistent Flags
+
+	// PassThroughUnknown keeps flags given to this component which are not
+	// defined, with their values, for PassThrough, instead of failing. An
+	// unknown flag takes the next argument as its value, unless the value is
+	// attached with "=" or the next argument is a flag.
+	PassThroughUnknown bool
+
+	// ValidateArgs checks the arguments of the command after parsing, before
+	// Function runs. An error is reported as a usage error matching
+	// ErrInvalidArguments.
+	ValidateArgs func(args []string) error
+
+	// RunWithoutSubcommand runs the Function of a component with
+	// subcommands when its arguments do not start with the name of one,
+	// passing the arguments to the Function.
+	RunWithoutSubcommand bool
+
+	// Fallback runs in place of failing when the arguments name a subcommand
+	// which is not defined, with that name as the first argument.
+	Fallback Func
+
+	// Resolve returns the subcommand named name when it is not one of
+	// Components, or nil if there is none, so subcommands can be created
+	// when they are run, such as from a directory or a remote catalog.
+	Resolve func(name string) *Component
+
+	parent   *Component
+	external string
+	fallback bool
+	delegate string
+
+	// index maps the names of the flags accepted by c to the flags, built by
+	// parse so each argument is looked up without scanning every flag.
+	index map[string]*Flag
+
+	*state
 }
 
 func (c *Component) Context() context.Context {
@@ -101,14 +301,42 @@ func (c *Component) Context() context.Context {
 }
 
 func (c *Component) Arguments() []string {
-	count := c.args.Size()
-	if len(c.flat) == 0 && count > 0 {
-		c.flat = make([]string, 0, count)
-		for i := 0; i < count; i++ {
-			c.flat = append(c.flat, c.args.Pop())
-		}
+	args, _ := c.args.remaining()
+	return args
+}
+
+// ExtraArguments returns the arguments following a "--" terminator, which are
+// not included in Arguments, or nil if there was no terminator.
+func (c *Component) ExtraArguments() []string {
+	_, extra := c.args.remaining()
+	return extra
+}
+
+// PassThrough returns the unknown flags given to a component with
+// PassThroughUnknown set, with their values, in the order given.
+func (c *Component) PassThrough() []string {
+	return c.unknown
+}
+
+// passUnknown keeps the unknown flag arg for PassThrough. Its value is the
+// value attached with "=", or else the next argument unless it is a flag.
+func (c *Component) passUnknown(arg string, attached bool) {
+	c.logger.Debug("babycli: passing through unknown flag", "flag", arg)
+	c.unknown = append(c.unknown, arg)
+	if !attached && !c.args.empty() && !strings.HasPrefix(c.args.peek(), "-") {
+		c.unknown = append(c.unknown, c.args.next())
+	}
+}
+
+// forwarded returns the arguments of c followed by the "--" terminator and
+// the extra arguments, if there was a terminator.
+func (c *Component) forwarded() []string {
+	args := slices.Clone(c.Arguments())
+	if extra := c.ExtraArguments(); extra != nil {
+		args = append(args, "--")
+		args = append(args, extra...)
 	}
-	return c.flat
+	return args
 }
 
 func (c *Component) Nargs() int {
@@ -119,202 +347,758 @@ func (c *Component) Leaf() bool {
 	return len(c.Components) == 0
 }
 
-func (c *Component) init() {
-	if c.vals == nil {
-		c.vals = &values{
-			strings:   make(map[string][]string, 0),
-			ints:      make(map[string][]int, 0),
-			bools:     make(map[string][]bool, 0),
-			durations: make(map[string][]time.Duration, 0),
-		}
+func (c *Component) runnable() bool {
+	return c.Function != nil || c.FunctionE != nil
+}
+
+// runsItself returns whether the remaining arguments are for the Function of
+// c, rather than naming a subcommand.
+func (c *Component) runsItself() bool {
+	switch {
+	case !c.runnable():
+		return false
+	case c.Leaf() && c.Resolve == nil:
+		return true
+	case !c.RunWithoutSubcommand:
+		return false
+	case c.args.empty():
+		return true
+	default:
+		next := c.args.peek()
+		return !c.Components.Contains(next) && next != helpComponent.Name
 	}
 }
 
-func (c *Component) run(output io.Writer) *result {
-	c.init()
+// parse consumes flags and resolves subcommands, returning the component
+// the arguments resolve to.
+func (c *Component) parse() (*Component, error) {
+	if err := c.validate(); err != nil {
+		c.logger.Error("babycli: invalid component", "name", c.Name, "error", err)
+		return nil, err
+	}
+	c.index = c.combine().index()
 
-	if !c.validate(output) {
-		return &result{code: Failure}
+	if c.parent == nil && c.err != nil {
+		return nil, c.attach(c.err)
 	}
 
-	for !c.args.Empty() {
-		if more := c.processFlags(); !more {
+	for !c.args.empty() {
+		more, err := c.processFlags()
+		if err != nil {
+			return nil, c.attach(err)
+		}
+		if !more {
 			break
 		}
 	}
 
-	if c.vals.helpSet() {
-		text := c.help()
-		write(output, text)
-		return &result{code: Success}
+	if c.vals.helpSet() || c.helpAllSet() || c.versionSet() {
+		return c, nil
 	}
 
-	if c.Leaf() && c.Function != nil {
-		code := c.Function(c)
-		if code == Usability {
-			text := c.help()
-			write(output, text)
-			return &result{code: Failure}
+	if c.runsItself() {
+		if err := c.check(); err != nil {
+			return nil, c.attach(err)
+		}
+		if err := c.validateArgs(); err != nil {
+			return nil, c.attach(err)
 		}
-		return &result{code: code}
+		return c, nil
+	}
+
+	var sub string
+	switch {
+	case !c.args.empty():
+		sub = c.args.next()
+	case c.Default != "":
+		sub = c.Default
+	default:
+		return c, nil
+	}
+
+	if sub == helpComponent.Name && !c.Components.Contains(sub) {
+		return c.helpTopic()
 	}
 
-	if c.args.Empty() {
-		text := c.help()
-		write(output, text)
-		return &result{code: Failure}
+	if cmd := c.child(sub); cmd != nil {
+		if err := cmd.available(); err != nil {
+			return nil, c.attach(err)
+		}
+		if cmd.Deprecated != "" {
+			c.Warnf("command %q is deprecated: %s", sub, cmd.Deprecated)
+		}
+		c.logger.Debug("babycli: resolved subcommand", "name", sub)
+		return cmd.parse()
 	}
 
-	sub := c.args.Pop()
-	cmd := c.Components.Get(sub)
-	cmd.args = c.args
-	cmd.vals = c.vals
-	cmd.globals = c.globals
-	cmd.context = c.context
-	return cmd.run(output)
+	aliased, err := c.alias(sub)
+	if err != nil {
+		return nil, c.attach(err)
+	}
+	if aliased {
+		return c.parse()
+	}
+	if !c.permits(sub) {
+		return nil, c.attach(parsef(ErrForbiddenCommand, "subcommand %q is not permitted", sub))
+	}
+	if path, exists := c.plugin(sub); exists {
+		c.logger.Debug("babycli: resolved plugin", "name", sub, "path", path)
+		c.external = path
+		return c, nil
+	}
+	if c.Fallback != nil {
+		c.logger.Debug("babycli: resolved fallback", "name", sub)
+		c.args.back()
+		c.fallback = true
+		if err := c.check(); err != nil {
+			return nil, c.attach(err)
+		}
+		return c, nil
+	}
+	if c.onUnknown != nil {
+		c.logger.Debug("babycli: delegating unknown command", "name", sub)
+		c.delegate = sub
+		return c, nil
+	}
+	return nil, c.attach(parsef(ErrUnknownCommand, "subcommand %q is not defined", sub))
+}
+
+func (c *Component) enabled() bool {
+	return c.Enabled == nil || c.Enabled()
 }
 
-func (c *Component) processFlags() bool {
-	arg := c.args.Peek()
+// unavailable returns the error for running c while it is not enabled.
+func (c *Component) unavailable() error {
+	if c.Unavailable != "" {
+		return &ParseError{Err: ErrUnavailableCommand, Message: c.Unavailable}
+	}
+	return parsef(ErrUnavailableCommand, "subcommand %q is not available", c.Name)
+}
 
+// allowed returns whether the Authorize hook of the run permits c.
+func (c *Component) allowed() bool {
+	return c.authorize == nil || c.parent == nil || c.authorize(c.path())
+}
+
+// available returns why the subcommand c may not be run, if it may not.
+func (c *Component) available() error {
 	switch {
+	case !c.enabled():
+		return c.unavailable()
+	case !c.allowed():
+		return parsef(ErrForbiddenCommand, "subcommand %q is not permitted", c.Name)
+	default:
+		return nil
+	}
+}
+
+// allowedComponents returns the subcommands of c listed in help.
+// permits reports whether Authorize allows running the subcommand name of c
+// which is not a component, such as a plugin or a name given to Fallback.
+func (c *Component) permits(name string) bool {
+	return c.authorize == nil || c.authorize(append(c.path(), name))
+}
+
+func (c *Component) allowedComponents() Components {
+	return slices.DeleteFunc(c.Components.visible(), func(cmd *Component) bool {
+		return !c.descend(cmd).allowed()
+	})
+}
+
+// child returns the subcommand of c named name, from Components or else from
+// Resolve, or nil if there is none.
+func (c *Component) child(name string) *Component {
+	if c.Components.Contains(name) {
+		return c.descend(c.Components.Get(name))
+	}
+	if c.Resolve == nil {
+		return nil
+	}
+	spec := c.Resolve(name)
+	if spec == nil {
+		return nil
+	}
+	cmd := c.descend(spec)
+	if cmd.Name == "" {
+		cmd.Name = name
+	}
+	return cmd
+}
+
+// check verifies the flags given for the resolved component c before its
+// Function runs, returning every problem found as one error.
+func (c *Component) check() error {
+	var errs []*ParseError
+	for _, f := range c.combine() {
+		if err := f.check(c.vals.given[f.Identity()]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinParse(errs)
+}
+
+// validateArgs checks the arguments of c with its ValidateArgs.
+func (c *Component) validateArgs() error {
+	if c.ValidateArgs == nil {
+		return nil
+	}
+	if err := c.ValidateArgs(c.Arguments()); err != nil {
+		return &ParseError{
+			Err:     errors.Join(ErrInvalidArguments, err),
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// descend returns a copy of the subcommand spec of c, sharing the state of
+// the run of c.
+func (c *Component) descend(spec *Component) *Component {
+	cmd := *spec
+	cmd.parent = c
+	cmd.state = c.state
+	return &cmd
+}
+
+// run acts on the component resolved by parse.
+func (c *Component) run() *result {
+	switch {
+	case c.vals.helpSet() || c.helpAllSet():
+		c.printHelp(c.stdout)
+		return &result{code: Success, kind: helpKind}
+	case c.versionSet():
+		write(c.stdout, c.version.String())
+		return &result{code: Success}
+	case c.external != "":
+		return c.exec(c.external)
+	case c.fallback:
+		return c.misused(c.execute())
+	case c.delegate != "":
+		return c.misused(&result{code: c.onUnknown(c, c.delegate, c.forwarded())})
+	case c.runnable() && (c.Leaf() || c.RunWithoutSubcommand):
+		return c.misused(c.execute())
+	default:
+		c.printHelp(c.stderr)
+		return &result{code: Failure, kind: usageKind}
+	}
+}
+
+// misused prints help to stderr when res reports misuse of the command, turning
+// a Usability code into a Failure.
+func (c *Component) misused(res *result) *result {
+	switch {
+	case res.code == Usability:
+		c.printHelp(c.stderr)
+		return &result{code: Failure, kind: usageKind}
+	case res.kind == usageKind:
+		c.printHelp(c.stderr)
+	}
+	return res
+}
+
+func (c *Component) processFlags() (bool, error) {
+	arg := c.args.peek()
+
+	switch {
+	case arg == "--":
+		c.args.terminate()
+		return false, nil
 	case strings.HasPrefix(arg, "--"):
-		c.consumeFlag()
-		return true
+		return true, c.consumeFlag()
 	case strings.HasPrefix(arg, "-"):
-		c.consumeFlag()
-		return true
+		return true, c.consumeFlag()
 	default:
-		return false
+		return false, nil
 	}
 }
 
-func (c *Component) maybeSplit(arg string) string {
+// splitFlag splits a value attached with "=" from the flag in arg, returning
+// the flag and the value with any surrounding quotes removed.
+func splitFlag(arg string) (name, value string, attached bool) {
 	equal := strings.Index(arg, "=")
 	if equal == -1 {
-		return arg
+		return arg, "", false
 	}
 
-	apostrophe := strings.Index(arg, "'")
-	if apostrophe == 0 {
-		return arg
+	quote := strings.IndexAny(arg, `'"`)
+	if quote == 0 {
+		return arg, "", false
 	}
 
-	if (equal < apostrophe) || (apostrophe == -1 && equal > 0) {
-		tokens := strings.SplitN(arg, "=", 2)
-		c.args.Push(tokens[1])
-		arg = tokens[0]
+	if (equal < quote) || (quote == -1 && equal > 0) {
+		return arg[:equal], unquote(arg[equal+1:]), true
 	}
 
-	return arg
+	return arg, "", false
 }
 
-func (c *Component) consumeFlag() {
-	combine := make(Flags, 0, len(c.Flags)+len(c.globals))
-	combine = append(combine, c.Flags...)
-	combine = append(combine, c.globals...)
+// unquote removes a pair of matching quotes surrounding s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
 
-	name := c.args.Pop()
-	name = c.maybeSplit(name)
+func (c *Component) consumeFlag() error {
+	arg := c.args.next()
+	name, value, attached := splitFlag(arg)
 
 	name = strings.TrimLeft(name, "-")
-	flag := combine.Get(name)
+	flag, exists := c.lookup(name)
+	if !exists {
+		return c.consumeUnknownFlag(arg, name, attached)
+	}
+	c.logger.Debug("babycli: parsing flag", "flag", flag.Identity(), "type", flag.Type)
+	if flag.Deprecated != "" && c.vals.given[flag.Identity()] == 0 {
+		c.Warnf("flag %q is deprecated: %s", flag.Identity(), flag.Deprecated)
+	}
+	c.vals.change(flag.Identity())
+
+	if !flag.Repeats && c.vals.count(flag) > 0 {
+		switch c.dups {
+		case DuplicatesLastWins:
+			c.vals.reset(flag)
+		case DuplicatesReject:
+			return parsef(ErrRepeatedFlag, "flag %q may only be given once", flag.Identity())
+		case DuplicatesDeferred:
+		}
+	}
+
+	// An attached value is read next, like one given as the next argument.
+	if attached {
+		c.args.replace(value)
+	}
+	c.attached = attached
+	defer func() { c.attached = false }()
 
 	switch flag.Type {
 	case BooleanFlag:
-		c.consumeBoolFlag(flag.Identity())
+		return c.consumeBoolFlag(flag, attached)
 	case StringFlag:
-		c.consumeStringFlag(flag.Identity())
+		return c.consumeStringFlag(flag)
 	case IntFlag:
-		c.consumeIntFlag(flag.Identity())
+		return c.consumeIntFlag(flag)
 	case DurationFlag:
-		c.consumeDurationFlag(flag.Identity())
+		return c.consumeDurationFlag(flag)
+	case SecretFlag:
+		return c.consumeSecretFlag(flag)
+	case DateFlag:
+		return c.consumeDateFlag(flag)
+	case BigIntFlag:
+		return c.consumeBigIntFlag(flag)
+	case BytesLiteralFlag:
+		return c.consumeBytesFlag(flag)
+	case OpenFileFlag:
+		return c.consumeFileFlag(flag)
+	case DirFlag:
+		return c.consumeDirFlag(flag)
+	case GlobFlag:
+		return c.consumeGlobFlag(flag)
+	}
+	return nil
+}
+
+// consumeUnknownFlag handles arg, naming a flag c does not accept, which may
+// be a cluster of verbosity flags, like "-vv", or passed through.
+func (c *Component) consumeUnknownFlag(arg, name string, attached bool) error {
+	combine := c.combine()
+	if f := combine.verbosityCluster(name); f != nil && !attached && !strings.HasPrefix(arg, "--") {
+		identity := f.Identity()
+		for range name {
+			c.vals.change(identity)
+			record(&c.vals.bools, identity, true)
+		}
+		return nil
+	}
+	if c.PassThroughUnknown {
+		c.passUnknown(arg, attached)
+		return nil
+	}
+	if suggestions := combine.Suggest(name, 0); len(suggestions) > 0 {
+		return parsef(ErrUnknownFlag, "flag %q is not defined, did you mean %s?", name, didYouMean(suggestions))
 	}
+	return parsef(ErrUnknownFlag, "flag %q is not defined", name)
 }
 
-func (c *Component) consumeBoolFlag(identity string) {
-	if c.args.Empty() {
-		c.vals.bools[identity] = append(c.vals.bools[identity], true)
-		return
+// consumeBoolFlag records the value of a boolean flag, which is optional
+// unless attached to the flag with "=".
+func (c *Component) consumeBoolFlag(flag *Flag, attached bool) error {
+	identity := flag.Identity()
+
+	if attached {
+		value, err := flag.transform(c.args.next())
+		if err != nil {
+			return err
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return parsef(ErrBadValue, "unable to convert value for flag %q to boolean %q", identity, value)
+		}
+		record(&c.vals.bools, identity, b)
+		return nil
 	}
 
-	next := c.args.Peek()
+	if c.args.empty() {
+		record(&c.vals.bools, identity, true)
+		return nil
+	}
+
+	next := c.args.peek()
 	switch {
 	case next == "true":
-		c.vals.bools[identity] = append(c.vals.bools[identity], true)
-		_ = c.args.Pop()
+		record(&c.vals.bools, identity, true)
+		_ = c.args.next()
 	case next == "false":
-		c.vals.bools[identity] = append(c.vals.bools[identity], false)
-		_ = c.args.Pop()
+		record(&c.vals.bools, identity, false)
+		_ = c.args.next()
 	default:
-		c.vals.bools[identity] = append(c.vals.bools[identity], true)
+		record(&c.vals.bools, identity, true)
+	}
+	return nil
+}
+
+// next pops the value attached to a flag, or else the argument following it
+// unless that is another flag.
+func (c *Component) next(flag *Flag) (string, error) {
+	if c.attached {
+		return c.args.next(), nil
 	}
+	if c.args.empty() || strings.HasPrefix(c.args.peek(), "-") {
+		return "", parsef(ErrMissingValue, "no value for %s flag %q", flag.Type, flag.Identity())
+	}
+	return c.args.next(), nil
 }
 
-func (c *Component) consumeStringFlag(identity string) {
-	if c.args.Empty() {
-		// TODO what about default values
-		panicf("no value for string flag %q", identity)
+// value pops the value following a flag, if there is one.
+func (c *Component) value(flag *Flag) (string, error) {
+	value, err := c.next(flag)
+	if err != nil {
+		return "", err
+	}
+	return c.prepare(flag, value)
+}
+
+// values pops the value following a flag, if there is one, split by the
+// Separator of the flag.
+func (c *Component) values(flag *Flag) ([]string, error) {
+	if flag.Separator == "" {
+		value, err := c.value(flag)
+		if err != nil {
+			return nil, err
+		}
+		return []string{value}, nil
+	}
+
+	value, err := c.next(flag)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(value, flag.Separator)
+	for i, part := range parts {
+		value, err := c.prepare(flag, part)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = value
+	}
+	return parts, nil
+}
+
+// prepare expands and transforms a value given for flag, and checks it is
+// one of its Choices.
+func (c *Component) prepare(flag *Flag, value string) (string, error) {
+	if flag.ExpandEnv {
+		value = c.expand(value)
+	}
+	value, err := flag.transform(value)
+	if err != nil {
+		return "", err
+	}
+	if len(flag.Choices) > 0 && !slices.Contains(flag.Choices, value) {
+		return "", parsef(ErrBadValue, "value %q for flag %q must be one of %s", value, flag.Identity(), flag.choices())
+	}
+	return value, nil
+}
+
+// expand replaces a leading "~" of value with the home directory, and the
+// environment variables in value with their values.
+func (c *Component) expand(value string) string {
+	var home string
+	if value == "~" || strings.HasPrefix(value, "~/") || strings.HasPrefix(value, "~"+string(filepath.Separator)) {
+		if home = c.getenv("HOME"); home == "" {
+			home, _ = os.UserHomeDir()
+		}
+	}
+	if home == "" {
+		return os.Expand(value, c.getenv)
+	}
+	return home + os.Expand(value[1:], c.getenv)
+}
+
+func (c *Component) consumeStringFlag(flag *Flag) error {
+	identity := flag.Identity()
+	values, err := c.values(flag)
+	if err != nil {
+		return err
 	}
+	record(&c.vals.strings, identity, values...)
+	return nil
+}
 
-	if strings.HasPrefix(c.args.Peek(), "-") {
-		panicf("no value for string flag %q", identity)
+func (c *Component) consumeIntFlag(flag *Flag) error {
+	identity := flag.Identity()
+	values, err := c.values(flag)
+	if err != nil {
+		return err
 	}
+	ints := make([]int, 0, len(values))
+	for _, value := range values {
+		i, err := strconv.Atoi(value)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			return parsef(ErrBadValue, "value %q for flag %q is out of range for int", value, identity)
+		case err != nil:
+			return parsef(ErrBadValue, "unable to convert value for flag %q to int %q", identity, value)
+		}
+		ints = append(ints, i)
+	}
+	record(&c.vals.ints, identity, ints...)
+	return nil
+}
 
-	value := c.args.Pop()
-	c.vals.strings[identity] = append(c.vals.strings[identity], value)
+func (c *Component) consumeDurationFlag(flag *Flag) error {
+	identity := flag.Identity()
+	values, err := c.values(flag)
+	if err != nil {
+		return err
+	}
+	durations := make([]time.Duration, 0, len(values))
+	for _, value := range values {
+		dur, err := time.ParseDuration(value)
+		if err != nil {
+			return parsef(ErrBadValue, "unable to convert value for flag %q to duration %q", identity, value)
+		}
+		durations = append(durations, dur)
+	}
+	record(&c.vals.durations, identity, durations...)
+	return nil
 }
 
-func (c *Component) consumeIntFlag(identity string) {
-	if c.args.Empty() {
-		// TODO what about default values
-		panicf("no value for int flag %q", identity)
+// consumeDateFlag records the value of a date flag as midnight UTC of the
+// day it names.
+func (c *Component) consumeDateFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
+	if err != nil {
+		return err
+	}
+	t, err := time.ParseInLocation(flag.layout(), value, time.UTC)
+	if err != nil {
+		return parsef(ErrBadValue, "unable to convert value for flag %q to date %q", identity, value)
 	}
+	year, month, day := t.Date()
+	record(&c.vals.dates, identity, time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	return nil
+}
 
-	if strings.HasPrefix(c.args.Peek(), "-") {
-		panicf("no value for int flag %q", identity)
+func (c *Component) consumeBigIntFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
+	if err != nil {
+		return err
 	}
+	i, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return parsef(ErrBadValue, "unable to convert value for flag %q to big integer %q", identity, value)
+	}
+	record(&c.vals.bigs, identity, i)
+	return nil
+}
 
-	value := c.args.Pop()
-	i, err := strconv.Atoi(value)
+// consumeBytesFlag records the value of a bytes flag, given in hex with a
+// "0x" prefix or else in base64.
+func (c *Component) consumeBytesFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
 	if err != nil {
-		panicf("unable to convert value for flag %q to int %q", identity, value)
+		return err
 	}
-	c.vals.ints[identity] = append(c.vals.ints[identity], i)
+	b, ok := flag.decode(value)
+	if !ok {
+		return parsef(ErrBadValue, "unable to decode value for flag %q as %s %q", identity, flag.Encoding, value)
+	}
+	record(&c.vals.bytes, identity, b)
+	return nil
 }
 
-func (c *Component) consumeDurationFlag(identity string) {
-	if c.args.Empty() {
-		// TODO what about default values
-		panicf("no value for string flag %q", identity)
+// consumeFileFlag records the path of a file flag, which must be a readable
+// file, or "-" for standard input.
+func (c *Component) consumeFileFlag(flag *Flag) error {
+	identity := flag.Identity()
+	if !c.args.empty() && c.args.peek() == "-" {
+		record(&c.vals.files, identity, c.args.next())
+		return nil
+	}
+	value, err := c.value(flag)
+	if err != nil {
+		return err
+	}
+	if c.example {
+		record(&c.vals.files, identity, value)
+		return nil
+	}
+	info, err := os.Stat(value)
+	switch {
+	case err != nil:
+		return parsef(ErrBadValue, "unable to use file %q for flag %q: %v", value, identity, errors.Unwrap(err))
+	case info.IsDir():
+		return parsef(ErrBadValue, "unable to use file %q for flag %q: is a directory", value, identity)
 	}
+	record(&c.vals.files, identity, value)
+	return nil
+}
 
-	if strings.HasPrefix(c.args.Peek(), "-") {
-		panicf("no value for string flag %q", identity)
+// consumeDirFlag records the path of a directory flag, which must be a
+// directory, or missing if it is created.
+func (c *Component) consumeDirFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
+	if err != nil {
+		return err
+	}
+	if c.example {
+		record(&c.vals.dirs, identity, value)
+		return nil
+	}
+	info, err := os.Stat(value)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && flag.CreateIfMissing:
+	case err != nil:
+		return parsef(ErrBadValue, "unable to use directory %q for flag %q: %v", value, identity, errors.Unwrap(err))
+	case !info.IsDir():
+		return parsef(ErrBadValue, "unable to use directory %q for flag %q: not a directory", value, identity)
 	}
+	record(&c.vals.dirs, identity, value)
+	return nil
+}
 
-	value := c.args.Pop()
-	dur, err := time.ParseDuration(value)
+// consumeGlobFlag records the paths matching the pattern given for a glob
+// flag.
+func (c *Component) consumeGlobFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
 	if err != nil {
-		panicf("unable to convert value for flag %q to duration %q", identity, value)
+		return err
 	}
-	c.vals.durations[identity] = append(c.vals.durations[identity], dur)
+	paths, err := flag.glob(value)
+	switch {
+	case err != nil:
+		return parsef(ErrBadValue, "unable to match pattern %q for flag %q: %v", value, identity, err)
+	case len(paths) == 0 && flag.Strict && !c.example:
+		return parsef(ErrBadValue, "pattern %q for flag %q matches no files", value, identity)
+	}
+	record(&c.vals.globs, identity, paths)
+	return nil
+}
+
+// createDirs creates the missing directories of the directory flags of c
+// with CreateIfMissing, given or by default.
+func (c *Component) createDirs() error {
+	for _, f := range c.combine() {
+		if f.Type != DirFlag || !f.CreateIfMissing {
+			continue
+		}
+		paths := c.vals.dirs[f.Identity()]
+		if len(paths) == 0 && f.Default != nil {
+			paths = []string{f.Default.value().(string)}
+		}
+		for _, path := range paths {
+			if err := os.MkdirAll(path, f.perm()); err != nil {
+				return fmt.Errorf("babycli: unable to create directory for flag %q: %w", f.Identity(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Count returns the number of times flag was given, by its long or short
+// name, whatever its type.
+func (c *Component) Count(flag string) int {
+	return c.vals.given[c.flagNamed(flag).Identity()]
+}
+
+// Changed reports whether flag was given on the command line, telling an
+// explicit value apart from a default or a prompted secret.
+func (c *Component) Changed(flag string) bool {
+	return c.vals.given[c.flagNamed(flag).Identity()] > 0
 }
 
 func (c *Component) HasString(flag string) bool {
 	return c.vals.stringCount(flag) > 0
 }
 
+// options returns the flags declared on c.
+func (c *Component) options() Flags {
+	return slices.Concat(c.Flags, c.Persistent)
+}
+
+// inherited returns the persistent flags of the ancestors of c, nearest first.
+func (c *Component) inherited() Flags {
+	var flags Flags
+	for p := c.parent; p != nil; p = p.parent {
+		flags = append(flags, p.Persistent...)
+	}
+	return flags
+}
+
+// ancestral returns the flags of the ancestors of c which are not persistent,
+// nearest first.
+func (c *Component) ancestral() Flags {
+	var flags Flags
+	for p := c.parent; p != nil; p = p.parent {
+		flags = append(flags, p.Flags...)
+	}
+	return flags
+}
+
+// combine returns every flag accepted by c, the most specific first. The flags
+// of ancestors are included so they may be given after a subcommand name, and
+// are listed with the globals in help.
 func (c *Component) combine() Flags {
-	return append(c.globals, c.Flags...)
+	return slices.Concat(c.options(), c.inherited(), c.ancestral(), c.globals)
+}
+
+// lookup returns the flag accepted by c with the long or short name, through
+// the index built by parse, if c was parsed.
+func (c *Component) lookup(name string) (*Flag, bool) {
+	if c.index != nil {
+		f, exists := c.index[name]
+		return f, exists
+	}
+	flags := c.combine()
+	if !flags.Contains(name) {
+		return nil, false
+	}
+	return flags.Get(name), true
+}
+
+// flagNamed returns the flag accepted by c with the long or short name, and
+// panics if there is none.
+func (c *Component) flagNamed(name string) *Flag {
+	f, exists := c.lookup(name)
+	if !exists {
+		panicf("flag %q is not defined", name)
+	}
+	return f
 }
 
 func (c *Component) GetString(flag string) string {
 	switch c.vals.stringCount(flag) {
 	case 0:
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return f.Default.Value.(string)
+			return f.Default.value().(string)
 		}
 		if f.Require {
 			panicf("no value for string flag %q", flag)
@@ -329,9 +1113,9 @@ func (c *Component) GetString(flag string) string {
 
 func (c *Component) GetStrings(flag string) []string {
 	if n := c.vals.stringCount(flag); n == 0 {
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return []string{f.Default.Value.(string)}
+			return []string{f.Default.value().(string)}
 		}
 		if f.Require {
 			panicf("no value for string flag %q", flag)
@@ -347,9 +1131,9 @@ func (c *Component) HasInt(flag string) bool {
 func (c *Component) GetInt(flag string) int {
 	switch c.vals.intCount(flag) {
 	case 0:
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return f.Default.Value.(int)
+			return f.Default.value().(int)
 		}
 		if f.Require {
 			panicf("no value for int flag %q", flag)
@@ -364,9 +1148,9 @@ func (c *Component) GetInt(flag string) int {
 
 func (c *Component) GetInts(flag string) []int {
 	if n := c.vals.intCount(flag); n == 0 {
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return []int{f.Default.Value.(int)}
+			return []int{f.Default.value().(int)}
 		}
 		if f.Require {
 			panicf("no value for int flag %q", flag)
@@ -382,9 +1166,9 @@ func (c *Component) HasDuration(flag string) bool {
 func (c *Component) GetDuration(flag string) time.Duration {
 	switch c.vals.durationCount(flag) {
 	case 0:
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return f.Default.Value.(time.Duration)
+			return f.Default.value().(time.Duration)
 		}
 		if f.Require {
 			panicf("no value for duration flag %q", flag)
@@ -398,10 +1182,10 @@ func (c *Component) GetDuration(flag string) time.Duration {
 }
 
 func (c *Component) GetDurations(flag string) []time.Duration {
-	if n := c.vals.intCount(flag); n == 0 {
-		f := c.Flags.Get(flag)
+	if n := c.vals.durationCount(flag); n == 0 {
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return []time.Duration{f.Default.Value.(time.Duration)}
+			return []time.Duration{f.Default.value().(time.Duration)}
 		}
 		if f.Require {
 			panicf("no value for duration flag %q", flag)
@@ -410,6 +1194,226 @@ func (c *Component) GetDurations(flag string) []time.Duration {
 	return slices.Clone(c.vals.durations[flag])
 }
 
+func (c *Component) HasDate(flag string) bool {
+	return c.vals.dateCount(flag) > 0
+}
+
+// GetDate returns the value of the date flag, at midnight UTC.
+func (c *Component) GetDate(flag string) time.Time {
+	switch c.vals.dateCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return f.Default.value().(time.Time)
+		}
+		if f.Require {
+			panicf("no value for date flag %q", flag)
+		}
+	case 1:
+		return c.vals.dates[flag][0]
+	default:
+		panicf("multiple values set for date flag %q", flag)
+	}
+	return time.Time{}
+}
+
+func (c *Component) GetDates(flag string) []time.Time {
+	if n := c.vals.dateCount(flag); n == 0 {
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return []time.Time{f.Default.value().(time.Time)}
+		}
+		if f.Require {
+			panicf("no value for date flag %q", flag)
+		}
+	}
+	return slices.Clone(c.vals.dates[flag])
+}
+
+func (c *Component) HasBigInt(flag string) bool {
+	return c.vals.bigCount(flag) > 0
+}
+
+// GetBigInt returns the value of the big integer flag, as a copy the caller
+// may modify.
+func (c *Component) GetBigInt(flag string) *big.Int {
+	switch c.vals.bigCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return new(big.Int).Set(f.Default.value().(*big.Int))
+		}
+		if f.Require {
+			panicf("no value for big integer flag %q", flag)
+		}
+	case 1:
+		return new(big.Int).Set(c.vals.bigs[flag][0])
+	default:
+		panicf("multiple values set for big integer flag %q", flag)
+	}
+	return new(big.Int)
+}
+
+func (c *Component) GetBigInts(flag string) []*big.Int {
+	if n := c.vals.bigCount(flag); n == 0 {
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return []*big.Int{new(big.Int).Set(f.Default.value().(*big.Int))}
+		}
+		if f.Require {
+			panicf("no value for big integer flag %q", flag)
+		}
+	}
+	bigs := make([]*big.Int, 0, len(c.vals.bigs[flag]))
+	for _, i := range c.vals.bigs[flag] {
+		bigs = append(bigs, new(big.Int).Set(i))
+	}
+	return bigs
+}
+
+func (c *Component) HasByteSlice(flag string) bool {
+	return c.vals.bytesCount(flag) > 0
+}
+
+// GetByteSlice returns the decoded value of the bytes flag, as a copy the
+// caller may modify.
+func (c *Component) GetByteSlice(flag string) []byte {
+	switch c.vals.bytesCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return slices.Clone(f.Default.value().([]byte))
+		}
+		if f.Require {
+			panicf("no value for bytes flag %q", flag)
+		}
+	case 1:
+		return slices.Clone(c.vals.bytes[flag][0])
+	default:
+		panicf("multiple values set for bytes flag %q", flag)
+	}
+	return nil
+}
+
+func (c *Component) GetByteSlices(flag string) [][]byte {
+	if n := c.vals.bytesCount(flag); n == 0 {
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return [][]byte{slices.Clone(f.Default.value().([]byte))}
+		}
+		if f.Require {
+			panicf("no value for bytes flag %q", flag)
+		}
+	}
+	values := make([][]byte, 0, len(c.vals.bytes[flag]))
+	for _, b := range c.vals.bytes[flag] {
+		values = append(values, slices.Clone(b))
+	}
+	return values
+}
+
+func (c *Component) HasFile(flag string) bool {
+	return c.vals.fileCount(flag) > 0
+}
+
+// GetFile opens the file given for the file flag, or its default path. The
+// path "-" opens standard input, which is not closed by Close. The caller
+// must close the file.
+func (c *Component) GetFile(flag string) (io.ReadCloser, error) {
+	var path string
+	switch c.vals.fileCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		switch {
+		case f.Default != nil:
+			path = f.Default.value().(string)
+		case f.Require:
+			panicf("no value for file flag %q", flag)
+		default:
+			return nil, fmt.Errorf("babycli: no value for file flag %q", flag)
+		}
+	case 1:
+		path = c.vals.files[flag][0]
+	default:
+		panicf("multiple values set for file flag %q", flag)
+	}
+
+	if path == "-" {
+		return io.NopCloser(c.stdin), nil
+	}
+	return os.Open(path)
+}
+
+func (c *Component) HasDir(flag string) bool {
+	return c.vals.dirCount(flag) > 0
+}
+
+func (c *Component) GetDir(flag string) string {
+	switch c.vals.dirCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return f.Default.value().(string)
+		}
+		if f.Require {
+			panicf("no value for directory flag %q", flag)
+		}
+	case 1:
+		return c.vals.dirs[flag][0]
+	default:
+		panicf("multiple values set for directory flag %q", flag)
+	}
+	return ""
+}
+
+func (c *Component) HasGlob(flag string) bool {
+	return c.vals.globCount(flag) > 0
+}
+
+// GetGlob returns the paths matching the pattern given for the glob flag, or
+// its default pattern.
+func (c *Component) GetGlob(flag string) []string {
+	switch c.vals.globCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return c.globDefault(f)
+		}
+		if f.Require {
+			panicf("no value for glob flag %q", flag)
+		}
+	case 1:
+		return slices.Clone(c.vals.globs[flag][0])
+	default:
+		panicf("multiple values set for glob flag %q", flag)
+	}
+	return nil
+}
+
+// GetGlobs returns the paths matching each pattern given for the glob flag,
+// in order, or its default pattern.
+func (c *Component) GetGlobs(flag string) []string {
+	if n := c.vals.globCount(flag); n == 0 {
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return c.globDefault(f)
+		}
+		if f.Require {
+			panicf("no value for glob flag %q", flag)
+		}
+	}
+	return slices.Concat(c.vals.globs[flag]...)
+}
+
+func (c *Component) globDefault(f *Flag) []string {
+	pattern := f.Default.value().(string)
+	paths, err := f.glob(pattern)
+	if err != nil {
+		panicf("unable to match default pattern %q for glob flag %q: %v", pattern, f.Identity(), err)
+	}
+	return paths
+}
+
 func (c *Component) HasBool(flag string) bool {
 	return c.vals.boolCount(flag) > 0
 }
@@ -417,9 +1421,9 @@ func (c *Component) HasBool(flag string) bool {
 func (c *Component) GetBool(flag string) bool {
 	switch c.vals.boolCount(flag) {
 	case 0:
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return f.Default.Value.(bool)
+			return f.Default.value().(bool)
 		}
 		if f.Require {
 			panicf("no value for boolean flag %q", flag)
@@ -434,9 +1438,9 @@ func (c *Component) GetBool(flag string) bool {
 
 func (c *Component) GetBools(flag string) []bool {
 	if n := c.vals.boolCount(flag); n == 0 {
-		f := c.combine().Get(flag)
+		f := c.flagNamed(flag)
 		if f.Default != nil {
-			return []bool{f.Default.Value.(bool)}
+			return []bool{f.Default.value().(bool)}
 		}
 		if f.Require {
 			panicf("no value for boolean flag %q", flag)
@@ -444,3 +1448,38 @@ func (c *Component) GetBools(flag string) []bool {
 	}
 	return slices.Clone(c.vals.bools[flag])
 }
+
+func (c *Component) Stdin() io.Reader {
+	return c.stdin
+}
+
+func (c *Component) Stdout() io.Writer {
+	return c.stdout
+}
+
+func (c *Component) Stderr() io.Writer {
+	return c.stderr
+}
+
+// Getenv returns the value of the environment variable key, as seen by the
+// Configuration that runs c.
+func (c *Component) Getenv(key string) string {
+	return c.getenv(key)
+}
+
+// Printf writes formatted output to Stdout.
+func (c *Component) Printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(c.stdout, format, args...)
+}
+
+// Warnf writes a formatted warning to the WarnOutput of the Configuration.
+// The format and its "warning: " prefix are translated by the Messages of the
+// Configuration.
+func (c *Component) Warnf(format string, args ...any) {
+	writef(c.warn, c.style.text("warning: ")+c.style.text(format), args...)
+}
+
+// Errorf writes a formatted message to Stderr.
+func (c *Component) Errorf(format string, args ...any) {
+	_, _ = fmt.Fprintf(c.stderr, format, args...)
+}
@@ -4,14 +4,19 @@
 package babycli
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/shoenig/test/must"
-	"noxide.lol/go/stacks"
 )
 
 type testCase struct {
@@ -529,7 +534,7 @@ func TestComponent_GetString(t *testing.T) {
 			name:     "required string not provided no default",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for string flag "name"`,
+			expPanic: "babycli: no value for string flag \"name\"\nUSAGE: --name <string> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -705,7 +710,7 @@ func TestComponent_GetStrings(t *testing.T) {
 			name:     "repeated strings not provided no default required",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for string flag "name"`,
+			expPanic: "babycli: no value for string flag \"name\"\nUSAGE: --name <string> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -826,7 +831,7 @@ func TestComponent_GetInt(t *testing.T) {
 			root: &Component{
 				Flags: Flags{
 					{
-						Type:    StringFlag,
+						Type:    IntFlag,
 						Long:    "age",
 						Require: true,
 						Default: &Default{
@@ -845,7 +850,7 @@ func TestComponent_GetInt(t *testing.T) {
 			name:     "required int not provided no default",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for int flag "age"`,
+			expPanic: "babycli: no value for integer flag \"age\"\nUSAGE: --age <integer> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -1000,7 +1005,7 @@ func TestComponent_GetInts(t *testing.T) {
 			name:     "repeated ints not provided no default required",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for int flag "age"`,
+			expPanic: "babycli: no value for integer flag \"age\"\nUSAGE: --age <integer> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -1102,7 +1107,7 @@ func TestComponent_GetDuration(t *testing.T) {
 						Long:    "ttl",
 						Require: true,
 						Default: &Default{
-							Value: 3,
+							Value: 3 * time.Minute,
 						},
 					},
 				},
@@ -1140,7 +1145,7 @@ func TestComponent_GetDuration(t *testing.T) {
 			name:     "required duration not provided no default",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for duration flag "ttl"`,
+			expPanic: "babycli: no value for duration flag \"ttl\"\nUSAGE: --ttl <duration> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -1295,7 +1300,7 @@ func TestComponent_GetDurations(t *testing.T) {
 			name:     "repeated durations not provided no default required",
 			expText:  "",
 			expCode:  Failure,
-			expPanic: `babycli: no value for duration flag "ttl"`,
+			expPanic: "babycli: no value for duration flag \"ttl\"\nUSAGE: --ttl <duration> [arguments...]\nRun with --help for more information.\n",
 			args:     nil,
 			root: &Component{
 				Flags: Flags{
@@ -1358,647 +1363,3733 @@ func TestComponent_GetDurations(t *testing.T) {
 	}
 }
 
-func TestComponent_GetBoolean(t *testing.T) {
+func TestComponent_GetDate(t *testing.T) {
 	t.Parallel()
 
-	var output string
-	var failure *strings.Builder
-
-	cases := []testCase{
+	cases := []struct {
+		name    string
+		args    []string
+		expFrom time.Time
+		expTo   time.Time
+		expOut  string
+	}{
 		{
-			name:    "required boolean provided no default",
-			expText: "hello true",
-			expCode: Success,
-			args:    []string{"--verbose", "true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name:    "defaults",
+			expFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 		},
 		{
-			name:    "required boolean provided with default",
-			expText: "hello true",
-			expCode: Success,
-			args:    []string{"--verbose", "true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
-						Default: &Default{
-							Value: true,
-						},
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name:    "date",
+			args:    []string{"--from", "2024-06-01", "--to=01/07/2024"},
+			expFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			expTo:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
 		},
 		{
-			name:    "required boolean provided implicit no default",
-			expText: "hello true",
-			expCode: Success,
-			args:    []string{"--verbose"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name:   "bad value",
+			args:   []string{"--from", "2024-06-31"},
+			expOut: "babycli: unable to convert value for flag \"from\" to date \"2024-06-31\"\nUSAGE: report [options] [arguments...]\nRun 'report --help' for more information.\n",
 		},
 		{
-			name:    "required boolean provided false no default",
-			expText: "hello false",
-			expCode: Success,
-			args:    []string{"--verbose", "false"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name:   "layout",
+			args:   []string{"--to", "2024-07-01"},
+			expOut: "babycli: unable to convert value for flag \"to\" to date \"2024-07-01\"\nUSAGE: report [options] [arguments...]\nRun 'report --help' for more information.\n",
 		},
-		{
-			name:    "required boolean not provided with default",
-			expText: "hello true",
-			expCode: Success,
-			args:    nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
-						Default: &Default{
-							Value: true,
-						},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var from, to time.Time
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "report",
+					Flags: Flags{
+						{Type: DateFlag, Long: "from", Default: &Default{Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+						{Type: DateFlag, Long: "to", Layout: "02/01/2006"},
 					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
-		},
-		{
-			name:     "required boolean not provided no default",
-			expText:  "",
-			expCode:  Failure,
-			expPanic: `babycli: no value for boolean flag "verbose"`,
-			args:     nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: true,
+					Function: func(c *Component) Code {
+						from, to = c.GetDate("from"), c.GetDate("to")
+						return Success
 					},
 				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
-		},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.expFrom, from)
+			must.Eq(t, tc.expTo, to)
+		})
+	}
+}
+
+func TestComponent_GetBigInt(t *testing.T) {
+	t.Parallel()
+
+	hint := "\nUSAGE: transfer [options] [arguments...]\nRun 'transfer --help' for more information.\n"
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    string
+		expOut string
+	}{
 		{
-			name:    "optional boolean not provided no default",
-			expText: "hello false",
-			expCode: Success,
-			args:    nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: false,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name: "default",
+			exp:  "1000000000000000000 0",
 		},
 		{
-			name:     "no repeat boolean provided twice",
-			expText:  "",
-			expCode:  Failure,
-			expPanic: `babycli: multiple values set for boolean flag "verbose"`,
-			args:     []string{"--verbose", "true", "--verbose", "true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: false,
-						Repeats: false,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name: "decimal",
+			args: []string{"--amount", "340282366920938463463374607431768211456"},
+			exp:  "340282366920938463463374607431768211456 0",
 		},
 		{
-			name:     "repeat boolean provided twice",
-			expText:  "",
-			expCode:  Failure,
-			expPanic: `babycli: multiple values set for boolean flag "verbose"`,
-			args:     []string{"--verbose", "true", "--verbose", "true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: false,
-						Repeats: true,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose") // must use GetBools
-					output = fmt.Sprintf("hello %t", verbose)
-					return Success
-				},
-			},
+			name: "hex",
+			args: []string{"--amount=0xffffffffffffffffff", "--block", "19000000"},
+			exp:  "4722366482869645213695 19000000",
 		},
 		{
-			name:    "use equal sign true",
-			expText: "ok true",
-			expCode: Success,
-			args:    []string{"--verbose=true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: false,
-						Repeats: false,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = "ok " + strconv.FormatBool(verbose)
-					return Success
-				},
-			},
+			name:   "bad value",
+			args:   []string{"--amount", "1e18"},
+			expOut: `babycli: unable to convert value for flag "amount" to big integer "1e18"` + hint,
 		},
 		{
-			name:    "use equal sign false",
-			expText: "ok false",
-			expCode: Success,
-			args:    []string{"--verbose=false"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Require: false,
-						Repeats: false,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBool("verbose")
-					output = "ok " + strconv.FormatBool(verbose)
-					return Success
-				},
-			},
+			name:   "int overflow",
+			args:   []string{"--block", "18446744073709551616"},
+			expOut: `babycli: value "18446744073709551616" for flag "block" is out of range for int` + hint,
 		},
 	}
 
 	for _, tc := range cases {
-		output = ""                    // reset for each case
-		failure = new(strings.Builder) // reset for each case
-
 		t.Run(tc.name, func(t *testing.T) {
-			config := &Configuration{
+			t.Parallel()
+
+			var result string
+			out := new(strings.Builder)
+			New(&Configuration{
 				Arguments: tc.args,
-				Top:       tc.root,
-				Output:    failure,
-			}
-			c := New(config)
-			result := c.Run()
-			must.Eq(t, tc.expText, output)
-			must.Eq(t, tc.expCode, result)
-			must.Eq(t, tc.expPanic, failure.String())
-		})
-	}
+				Output:    out,
+				Top: &Component{
+					Name: "transfer",
+					Flags: Flags{
+						{Type: BigIntFlag, Long: "amount", Default: &Default{Value: big.NewInt(1e18)}},
+						{Type: IntFlag, Long: "block", Default: &Default{Value: 0}},
+					},
+					Function: func(c *Component) Code {
+						amount := c.GetBigInt("amount")
+						result = fmt.Sprintf("%s %d", amount, c.GetInt("block"))
+						amount.SetInt64(0)
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
+
+func TestComponent_GetByteSlice(t *testing.T) {
+	t.Parallel()
+
+	hint := "\nUSAGE: seal [options] [arguments...]\nRun 'seal --help' for more information.\n"
+
+	cases := []struct {
+		name     string
+		encoding Encoding
+		args     []string
+		exp      []byte
+		expOut   string
+	}{
+		{
+			name: "default",
+			exp:  []byte{0},
+		},
+		{
+			name:     "hex",
+			encoding: Hex,
+			args:     []string{"--nonce", "0xdeadbeef"},
+			exp:      []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			name:     "hex unprefixed",
+			encoding: Hex,
+			args:     []string{"--nonce", "00ff"},
+			exp:      []byte{0x00, 0xff},
+		},
+		{
+			name: "base64",
+			args: []string{"--nonce", "3q2+7w=="},
+			exp:  []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			name: "base64 not hex",
+			args: []string{"--nonce", "00ff"},
+			exp:  []byte{0xd3, 0x47, 0xdf},
+		},
+		{
+			name:     "base64 url unpadded",
+			encoding: Base64URL,
+			args:     []string{"--nonce=3q2-7w"},
+			exp:      []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			name:   "base64 url alphabet",
+			args:   []string{"--nonce=3q2-7w"},
+			expOut: "babycli: unable to decode value for flag \"nonce\" as base64 \"3q2-7w\"" + hint,
+		},
+		{
+			name:     "bad value",
+			encoding: Hex,
+			args:     []string{"--nonce", "0xdeadbee"},
+			expOut:   "babycli: unable to decode value for flag \"nonce\" as hex \"0xdeadbee\"" + hint,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var nonce []byte
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "seal",
+					Flags: Flags{
+						{Type: BytesLiteralFlag, Long: "nonce", Encoding: tc.encoding, Default: &Default{Value: []byte{0}}},
+					},
+					Function: func(c *Component) Code {
+						nonce = c.GetByteSlice("nonce")
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.exp, nonce)
+		})
+	}
+}
+
+func TestComponent_GetFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	must.NoError(t, os.WriteFile(input, []byte("from file"), 0o644))
+
+	hint := "\nUSAGE: cat [options] [arguments...]\nRun 'cat --help' for more information.\n"
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    string
+		expOut string
+	}{
+		{
+			name: "file",
+			args: []string{"--input", input},
+			exp:  "from file",
+		},
+		{
+			name: "stdin",
+			args: []string{"--input", "-"},
+			exp:  "from stdin",
+		},
+		{
+			name: "not given",
+			exp:  `babycli: no value for file flag "input"`,
+		},
+		{
+			name:   "missing",
+			args:   []string{"--input", filepath.Join(dir, "missing.txt")},
+			expOut: fmt.Sprintf("babycli: unable to use file %q for flag \"input\": no such file or directory", filepath.Join(dir, "missing.txt")) + hint,
+		},
+		{
+			name:   "directory",
+			args:   []string{"--input", dir},
+			expOut: fmt.Sprintf("babycli: unable to use file %q for flag \"input\": is a directory", dir) + hint,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result string
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Stdin:     strings.NewReader("from stdin"),
+				Top: &Component{
+					Name:  "cat",
+					Flags: Flags{{Type: OpenFileFlag, Long: "input"}},
+					Function: func(c *Component) Code {
+						f, err := c.GetFile("input")
+						if err != nil {
+							result = err.Error()
+							return Failure
+						}
+						defer f.Close()
+						b, err := io.ReadAll(f)
+						must.NoError(t, err)
+						result = string(b)
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
+
+func TestComponent_GetDir(t *testing.T) {
+	t.Parallel()
+
+	hint := "\nUSAGE: build --cache <directory> [options] [arguments...]\nRun 'build --help' for more information.\n"
+
+	cases := []struct {
+		name    string
+		args    func(dir string) []string
+		expOut  func(dir string) string
+		expDirs []string
+	}{
+		{
+			name: "existing",
+			args: func(dir string) []string {
+				return []string{"--cache", dir}
+			},
+			expDirs: []string{"out"},
+		},
+		{
+			name: "created",
+			args: func(dir string) []string {
+				return []string{"--cache", dir, "--out", filepath.Join(dir, "a", "b")}
+			},
+			expDirs: []string{"a/b"},
+		},
+		{
+			name: "missing",
+			args: func(dir string) []string {
+				return []string{"--cache", filepath.Join(dir, "cache")}
+			},
+			expOut: func(dir string) string {
+				return fmt.Sprintf("babycli: unable to use directory %q for flag \"cache\": no such file or directory", filepath.Join(dir, "cache")) + hint
+			},
+		},
+		{
+			name: "file",
+			args: func(dir string) []string {
+				return []string{"--cache", dir, "--out", filepath.Join(dir, "file.txt")}
+			},
+			expOut: func(dir string) string {
+				return fmt.Sprintf("babycli: unable to use directory %q for flag \"out\": not a directory", filepath.Join(dir, "file.txt")) + hint
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			must.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644))
+
+			ran := false
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args(dir),
+				Output:    out,
+				Top: &Component{
+					Name: "build",
+					Flags: Flags{
+						{Type: DirFlag, Long: "cache", Require: true},
+						{Type: DirFlag, Long: "out", CreateIfMissing: true, Perm: 0o700, Default: &Default{Value: filepath.Join(dir, "out")}},
+					},
+					Function: func(c *Component) Code {
+						info, err := os.Stat(c.GetDir("out"))
+						must.NoError(t, err)
+						must.Eq(t, 0o700, info.Mode().Perm())
+						ran = true
+						return Success
+					},
+				},
+			}).Run()
+
+			if tc.expOut != nil {
+				must.Eq(t, tc.expOut(dir), out.String())
+				must.False(t, ran)
+				return
+			}
+			must.Eq(t, "", out.String())
+			must.True(t, ran)
+			for _, d := range tc.expDirs {
+				must.DirExists(t, filepath.Join(dir, d))
+			}
+		})
+	}
+}
+
+func TestComponent_GetGlob(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.txt"} {
+		must.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	hint := "\nUSAGE: fmt [options] [arguments...]\nRun 'fmt --help' for more information.\n"
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    []string
+		expOut string
+	}{
+		{
+			name: "default",
+			exp:  []string{path("c.txt")},
+		},
+		{
+			name: "pattern",
+			args: []string{"--files", path("*.go")},
+			exp:  []string{path("a.go"), path("b.go")},
+		},
+		{
+			name: "repeated",
+			args: []string{"--files", path("*.txt"), "--files", path("a.*")},
+			exp:  []string{path("c.txt"), path("a.go")},
+		},
+		{
+			name: "no match",
+			args: []string{"--files", path("*.md")},
+			exp:  nil,
+		},
+		{
+			name:   "strict",
+			args:   []string{"--strict", path("*.md")},
+			expOut: fmt.Sprintf("babycli: pattern %q for flag \"strict\" matches no files", path("*.md")) + hint,
+		},
+		{
+			name:   "bad pattern",
+			args:   []string{"--files", "["},
+			expOut: "babycli: unable to match pattern \"[\" for flag \"files\": syntax error in pattern" + hint,
+		},
+		{
+			name: "match",
+			args: []string{"--deep", "**/*.go"},
+			exp:  []string{"pkg/a.go", "pkg/sub/b.go"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var files []string
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "fmt",
+					Flags: Flags{
+						{Type: GlobFlag, Long: "files", Repeats: true, Default: &Default{Value: path("*.txt")}},
+						{Type: GlobFlag, Long: "strict", Strict: true},
+						{
+							Type: GlobFlag,
+							Long: "deep",
+							Match: func(pattern string) ([]string, error) {
+								return []string{"pkg/a.go", "pkg/sub/b.go"}, nil
+							},
+						},
+					},
+					Function: func(c *Component) Code {
+						switch {
+						case c.HasGlob("deep"):
+							files = c.GetGlob("deep")
+						case c.HasGlob("strict"):
+							files = c.GetGlob("strict")
+						default:
+							files = c.GetGlobs("files")
+						}
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.exp, files)
+		})
+	}
+}
+
+func TestComponent_GetDurations_lookup(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
+		{
+			name: "defaults",
+			args: []string{"serve"},
+			exp:  "[30s] [] [1m0s]",
+		},
+		{
+			name: "given with default",
+			args: []string{"serve", "--interval", "1m,5m"},
+			exp:  "[1m0s 5m0s] [] [1m0s]",
+		},
+		{
+			name: "inherited",
+			args: []string{"--timeout", "2m", "serve", "--wait", "10s", "--wait", "20s"},
+			exp:  "[30s] [10s 20s] [2m0s]",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result string
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Globals: Flags{
+					{Type: DurationFlag, Long: "timeout", Default: &Default{Value: time.Minute}},
+				},
+				Top: &Component{
+					Name: "tool",
+					Persistent: Flags{
+						{Type: DurationFlag, Long: "wait", Repeats: true},
+					},
+					Components: Components{
+						{
+							Name: "serve",
+							Flags: Flags{
+								{Type: DurationFlag, Long: "interval", Repeats: true, Separator: ",", Default: &Default{Value: 30 * time.Second}},
+							},
+							Function: func(c *Component) Code {
+								result = fmt.Sprint(c.GetDurations("interval"), c.GetDurations("wait"), c.GetDurations("timeout"))
+								return Success
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, Success, code)
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
+
+func TestFlag_Separator(t *testing.T) {
+	t.Parallel()
+
+	hint := "\nUSAGE: serve [options] [arguments...]\nRun 'serve --help' for more information.\n"
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    string
+		expOut string
+	}{
+		{
+			name: "split",
+			args: []string{"--port", "80,443", "--port=8080", "--interval", "1m,5m", "--tag", "a,b"},
+			exp:  "[80 443 8080] [1m0s 5m0s] [a b]",
+		},
+		{
+			name: "single",
+			args: []string{"--port", "80", "--interval", "1m", "--tag", "a"},
+			exp:  "[80] [1m0s] [a]",
+		},
+		{
+			name:   "bad part",
+			args:   []string{"--port", "80,https"},
+			expOut: `babycli: unable to convert value for flag "port" to int "https"` + hint,
+		},
+		{
+			name:   "choices",
+			args:   []string{"--tag", "a,c"},
+			expOut: `babycli: value "c" for flag "tag" must be one of [a|b]` + hint,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result string
+			out := new(strings.Builder)
+			New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "serve",
+					Flags: Flags{
+						{Type: IntFlag, Long: "port", Repeats: true, Separator: ","},
+						{Type: DurationFlag, Long: "interval", Repeats: true, Separator: ","},
+						{Type: StringFlag, Long: "tag", Repeats: true, Separator: ",", Choices: []string{"a", "b"}},
+					},
+					Function: func(c *Component) Code {
+						result = fmt.Sprint(c.GetInts("port"), c.GetDurations("interval"), c.GetStrings("tag"))
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
+
+func TestComponent_GetBoolean(t *testing.T) {
+	t.Parallel()
+
+	var output string
+	var failure *strings.Builder
+
+	cases := []testCase{
+		{
+			name:    "required boolean provided no default",
+			expText: "hello true",
+			expCode: Success,
+			args:    []string{"--verbose", "true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "required boolean provided with default",
+			expText: "hello true",
+			expCode: Success,
+			args:    []string{"--verbose", "true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+						Default: &Default{
+							Value: true,
+						},
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "required boolean provided implicit no default",
+			expText: "hello true",
+			expCode: Success,
+			args:    []string{"--verbose"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "required boolean provided false no default",
+			expText: "hello false",
+			expCode: Success,
+			args:    []string{"--verbose", "false"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "required boolean not provided with default",
+			expText: "hello true",
+			expCode: Success,
+			args:    nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+						Default: &Default{
+							Value: true,
+						},
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:     "required boolean not provided no default",
+			expText:  "",
+			expCode:  Failure,
+			expPanic: "babycli: no value for boolean flag \"verbose\"\nUSAGE: --verbose [arguments...]\nRun with --help for more information.\n",
+			args:     nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "optional boolean not provided no default",
+			expText: "hello false",
+			expCode: Success,
+			args:    nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:     "no repeat boolean provided twice",
+			expText:  "",
+			expCode:  Failure,
+			expPanic: `babycli: multiple values set for boolean flag "verbose"`,
+			args:     []string{"--verbose", "true", "--verbose", "true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: false,
+						Repeats: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:     "repeat boolean provided twice",
+			expText:  "",
+			expCode:  Failure,
+			expPanic: `babycli: multiple values set for boolean flag "verbose"`,
+			args:     []string{"--verbose", "true", "--verbose", "true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: false,
+						Repeats: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose") // must use GetBools
+					output = fmt.Sprintf("hello %t", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "use equal sign true",
+			expText: "ok true",
+			expCode: Success,
+			args:    []string{"--verbose=true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: false,
+						Repeats: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = "ok " + strconv.FormatBool(verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "use equal sign false",
+			expText: "ok false",
+			expCode: Success,
+			args:    []string{"--verbose=false"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Require: false,
+						Repeats: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBool("verbose")
+					output = "ok " + strconv.FormatBool(verbose)
+					return Success
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output = ""                    // reset for each case
+		failure = new(strings.Builder) // reset for each case
+
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top:       tc.root,
+				Output:    failure,
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expText, output)
+			must.Eq(t, tc.expCode, result)
+			must.Eq(t, tc.expPanic, failure.String())
+		})
+	}
+}
+
+func TestComponent_GetBooleans(t *testing.T) {
+	t.Parallel()
+
+	var output string
+	var failure *strings.Builder
+
+	cases := []testCase{
+		{
+			name:    "repeated boolens provided no default",
+			expText: "hello [true false true]",
+			expCode: Success,
+			args:    []string{"--verbose", "true", "--verbose", "false", "--verbose", "true"},
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Repeats: true,
+						Require: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBools("verbose")
+					output = fmt.Sprintf("hello %v", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "repeated booleans not provided no default not required",
+			expText: "hello []",
+			expCode: Success,
+			args:    nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Repeats: true,
+						Require: false,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBools("verbose")
+					output = fmt.Sprintf("hello %v", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:     "repeated booleans not provided no default required",
+			expText:  "",
+			expCode:  Failure,
+			expPanic: "babycli: no value for boolean flag \"verbose\"\nUSAGE: --verbose [arguments...]\nRun with --help for more information.\n",
+			args:     nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Repeats: true,
+						Require: true,
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBools("verbose")
+					output = fmt.Sprintf("hello %v", verbose)
+					return Success
+				},
+			},
+		},
+		{
+			name:    "repeated booleans not provided with default required",
+			expText: "hello [true]",
+			expCode: Success,
+			args:    nil,
+			root: &Component{
+				Flags: Flags{
+					{
+						Type:    BooleanFlag,
+						Long:    "verbose",
+						Repeats: true,
+						Require: true,
+						Default: &Default{
+							Value: true,
+						},
+					},
+				},
+				Function: func(c *Component) Code {
+					verbose := c.GetBools("verbose")
+					output = fmt.Sprintf("hello %v", verbose)
+					return Success
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output = ""                    // reset for each case
+		failure = new(strings.Builder) // reset for each case
+
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top:       tc.root,
+				Output:    failure,
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expText, output)
+			must.Eq(t, tc.expCode, result)
+			must.Eq(t, tc.expPanic, failure.String())
+		})
+	}
+}
+
+func Test_splitFlag(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		arg   string
+		exp   string
+		value string
+	}{
+		{
+			name: "plain",
+			arg:  "-name",
+			exp:  "-name",
+		},
+		{
+			name:  "split",
+			arg:   "-name=bob",
+			exp:   "-name",
+			value: "bob",
+		},
+		{
+			name: "quote",
+			arg:  "'a=b'",
+			exp:  "'a=b'",
+		},
+		{
+			name:  "quote split",
+			arg:   "-name='bob dylan'",
+			exp:   "-name",
+			value: "bob dylan",
+		},
+		{
+			name:  "double quote split",
+			arg:   `--name="bob dylan"`,
+			exp:   "--name",
+			value: "bob dylan",
+		},
+		{
+			name:  "quoted equals",
+			arg:   "--opt='a=b'",
+			exp:   "--opt",
+			value: "a=b",
+		},
+		{
+			name:  "unmatched quote",
+			arg:   "--opt='a",
+			exp:   "--opt",
+			value: "'a",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, value, attached := splitFlag(tc.arg)
+			must.Eq(t, tc.exp, name)
+			must.Eq(t, tc.value, value)
+			must.Eq(t, tc.value != "", attached)
+		})
+	}
+}
+
+func TestGlobal_flag_bool(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	config := &Configuration{
+		Top: &Component{
+			Function: func(c *Component) Code {
+				output = fmt.Sprintf("v is %t", c.GetBool("verbose"))
+				return Success
+			},
+		},
+		Globals: Flags{
+			{
+				Long: "verbose",
+				Default: &Default{
+					Value: true,
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Zero(t, result)
+	must.Eq(t, "v is true", output)
+}
+
+func TestGlobal_flag_string(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	config := &Configuration{
+		Top: &Component{
+			Function: func(c *Component) Code {
+				output = "name is " + c.GetString("name")
+				return Success
+			},
+		},
+		Globals: Flags{
+			{
+				Long: "name",
+				Default: &Default{
+					Value: "billy",
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Zero(t, result)
+	must.Eq(t, "name is billy", output)
+}
+
+func TestGlobal_flag_int(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	config := &Configuration{
+		Top: &Component{
+			Function: func(c *Component) Code {
+				output = fmt.Sprintf("age is %d", c.GetInt("age"))
+				return Success
+			},
+		},
+		Globals: Flags{
+			{
+				Long: "age",
+				Default: &Default{
+					Value: 32,
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Zero(t, result)
+	must.Eq(t, "age is 32", output)
+}
+
+func TestGlobal_flag_duration(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	config := &Configuration{
+		Top: &Component{
+			Function: func(c *Component) Code {
+				output = fmt.Sprintf("ttl is %s", c.GetDuration("ttl"))
+				return Success
+			},
+		},
+		Globals: Flags{
+			{
+				Long: "ttl",
+				Default: &Default{
+					Value: 2 * time.Minute,
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Zero(t, result)
+	must.Eq(t, "ttl is 2m0s", output)
+}
+
+func TestArguments_top(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	cases := []struct {
+		name    string
+		args    []string
+		expText string
+	}{
+		{
+			name:    "none",
+			args:    []string{},
+			expText: "hello []",
+		},
+		{
+			name:    "one",
+			args:    []string{"one"},
+			expText: "hello [one]",
+		},
+		{
+			name:    "two",
+			args:    []string{"one", "two"},
+			expText: "hello [one two]",
+		},
+		{
+			name:    "three",
+			args:    []string{"one", "two", "three"},
+			expText: "hello [one two three]",
+		},
+	}
+
+	for _, tc := range cases {
+		output = "" // reset
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Function: func(c *Component) Code {
+						output = fmt.Sprintf("hello %v", c.Arguments())
+						return Success
+					},
+				},
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expText, output)
+			must.Eq(t, Success, result)
+		})
+	}
+}
+
+func TestArguments_child(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	cases := []struct {
+		name    string
+		args    []string
+		expText string
+	}{
+		{
+			name:    "none",
+			args:    []string{"child"},
+			expText: "hi []",
+		},
+		{
+			name:    "one",
+			args:    []string{"child", "one"},
+			expText: "hi [one]",
+		},
+		{
+			name:    "two",
+			args:    []string{"child", "one", "two"},
+			expText: "hi [one two]",
+		},
+		{
+			name:    "three",
+			args:    []string{"child", "one", "two", "three"},
+			expText: "hi [one two three]",
+		},
+	}
+
+	for _, tc := range cases {
+		output = "" // reset
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Components: Components{
+						{
+							Name: "child",
+							Function: func(c *Component) Code {
+								output = fmt.Sprintf("hi %v", c.Arguments())
+								return Success
+							},
+						},
+					},
+				},
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expText, output)
+			must.Eq(t, Success, result)
+		})
+	}
+}
+
+func TestArguments_repeated(t *testing.T) {
+	t.Parallel()
+
+	args := []string{"child", "--name=bob", "one", "two", "--", "three"}
+	config := &Configuration{
+		Arguments: args,
+		Top: &Component{
+			Components: Components{
+				{
+					Name: "child",
+					Flags: Flags{
+						{Type: StringFlag, Long: "name"},
+					},
+					Function: func(c *Component) Code {
+						must.Eq(t, []string{"three"}, c.ExtraArguments())
+						must.Eq(t, []string{"one", "two"}, c.Arguments())
+						must.Eq(t, []string{"one", "two"}, c.Arguments())
+						must.Eq(t, []string{"three"}, c.ExtraArguments())
+						must.Eq(t, "bob", c.GetString("name"))
+						return Success
+					},
+				},
+			},
+		},
+	}
+	must.Eq(t, Success, New(config).Run())
+	must.Eq(t, []string{"child", "--name=bob", "one", "two", "--", "three"}, args)
+}
+
+func TestRun_defaultCommand(t *testing.T) {
+	t.Parallel()
+
+	var output string
+
+	cases := []struct {
+		name    string
+		args    []string
+		expText string
+	}{
+		{
+			name:    "implicit",
+			args:    nil,
+			expText: "status is good",
+		},
+		{
+			name:    "explicit",
+			args:    []string{"status"},
+			expText: "status is good",
+		},
+		{
+			name:    "other",
+			args:    []string{"version"},
+			expText: "version is 1.0",
+		},
+	}
+
+	for _, tc := range cases {
+		output = "" // reset
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Default: "status",
+					Components: Components{
+						{
+							Name: "status",
+							Function: func(*Component) Code {
+								output = "status is good"
+								return Success
+							},
+						},
+						{
+							Name: "version",
+							Function: func(*Component) Code {
+								output = "version is 1.0"
+								return Success
+							},
+						},
+					},
+				},
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expText, output)
+			must.Eq(t, Success, result)
+		})
+	}
+}
+
+func TestHelp_categories(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "program",
+		Components: Components{
+			{Name: "start", Help: "start it", Category: "management"},
+			{Name: "about", Help: "about it"},
+			{Name: "trace", Help: "trace it", Category: "debug"},
+			{Name: "stop", Help: "stop it", Category: "management"},
+		},
+		state: new(state),
+	}
+
+	text := top.help()
+	must.StrContains(t, text, "COMMANDS:\n  about - about it\n")
+	must.StrContains(t, text, "MANAGEMENT COMMANDS:\n  start - start it\n  stop  - stop it\n")
+	must.StrContains(t, text, "DEBUG COMMANDS:\n  trace - trace it")
+	must.Less(t, strings.Index(text, "DEBUG"), strings.Index(text, "MANAGEMENT"))
+}
+
+func TestHelp_groups(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "program",
+		Flags: Flags{
+			{Type: StringFlag, Long: "host", Help: "server host", Group: "connection"},
+			{Type: BooleanFlag, Long: "dry-run", Help: "change nothing"},
+			{Type: StringFlag, Long: "format", Help: "output format", Group: "output"},
+			{Type: IntFlag, Long: "port", Help: "server port", Group: "connection"},
+		},
+		Function: func(*Component) Code { return Success },
+		state:    new(state),
+	}
+
+	text := top.help()
+	must.StrContains(t, text, "OPTIONS:\n--dry-run   boolean - change nothing\n")
+	must.StrContains(t, text, "CONNECTION OPTIONS:\n--host    string - server host\n--port   integer - server port\n")
+	must.StrContains(t, text, "OUTPUT OPTIONS:\n--format   string - output format")
+	must.Less(t, strings.Index(text, "OUTPUT"), strings.Index(text, "CONNECTION"))
+}
+
+func TestHelp_globalsHelp(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		mode      GlobalsHelp
+		expTop    string
+		expDeploy string
+	}{
+		{
+			name:      "listed",
+			mode:      GlobalsListed,
+			expTop:    "GLOBALS:\n--debug     boolean - print debug logs\n--help/-h   boolean - print help message",
+			expDeploy: "GLOBALS:\n--debug     boolean - print debug logs\n--help/-h   boolean - print help message",
+		},
+		{
+			name:      "collapsed",
+			mode:      GlobalsCollapsed,
+			expTop:    "GLOBALS:\n--debug     boolean - print debug logs\n--help/-h   boolean - print help message",
+			expDeploy: "GLOBALS:\n  run \"tool --help\" to list the global options",
+		},
+		{
+			name:      "hidden",
+			mode:      GlobalsHidden,
+			expTop:    "GLOBALS:\n--debug     boolean - print debug logs\n--help/-h   boolean - print help message",
+			expDeploy: "OPTIONS:\n--env   string - environment",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(&Configuration{
+				Globals:     Flags{{Type: BooleanFlag, Long: "debug", Help: "print debug logs"}},
+				GlobalsHelp: tc.mode,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name:     "deploy",
+							Flags:    Flags{{Type: StringFlag, Long: "env", Help: "environment"}},
+							Function: func(*Component) Code { return Success },
+						},
+					},
+				},
+			})
+			must.StrHasSuffix(t, tc.expTop, r.HelpText())
+			must.StrHasSuffix(t, tc.expDeploy, r.HelpText("deploy"))
+		})
+	}
+}
+
+func TestRun_helpAll(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Help: "a tool",
+		Components: Components{
+			{
+				Name: "config",
+				Help: "manage configuration",
+				Components: Components{
+					{Name: "get", Help: "print a value", Function: func(*Component) Code { return Failure }},
+					{Name: "set", Function: func(*Component) Code { return Failure }},
+					{Name: "debug", Hidden: true, Function: func(*Component) Code { return Failure }},
+				},
+			},
+			{Name: "deploy", Help: "deploy it", Function: func(*Component) Code { return Failure }},
+		},
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
+		{
+			name: "flag",
+			args: []string{"--help-all"},
+			exp:  "tool - a tool\n  config - manage configuration\n    get - print a value\n    set\n  deploy - deploy it\n",
+		},
+		{
+			name: "subcommand flag",
+			args: []string{"config", "--help-all"},
+			exp:  "tool config - manage configuration\n  get - print a value\n  set\n",
+		},
+		{
+			name: "help command",
+			args: []string{"help", "--all"},
+			exp:  "tool - a tool\n  config - manage configuration\n    get - print a value\n    set\n  deploy - deploy it\n",
+		},
+		{
+			name: "help command topic",
+			args: []string{"help", "config", "--all"},
+			exp:  "tool config - manage configuration\n  get - print a value\n  set\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{Arguments: tc.args, Top: top, Output: out}).Run()
+			must.Eq(t, Success, code)
+			must.Eq(t, tc.exp, out.String())
+		})
+	}
+}
+
+func TestParse_passThroughUnknown(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		args        []string
+		expPass     []string
+		expArgs     []string
+		expVerbose  bool
+		expEnv      string
+		expErr      string
+		passThrough bool
+	}{
+		{
+			name:        "values",
+			args:        []string{"--env", "dev", "--color=always", "-x", "--depth", "3", "file"},
+			expPass:     []string{"--color=always", "-x", "--depth", "3"},
+			expArgs:     []string{"file"},
+			expEnv:      "dev",
+			passThrough: true,
+		},
+		{
+			name:        "flags between",
+			args:        []string{"--cache", "-v", "--mode", "fast", "--env=prod", "--", "file"},
+			expPass:     []string{"--cache", "--mode", "fast"},
+			expVerbose:  true,
+			expEnv:      "prod",
+			passThrough: true,
+		},
+		{
+			name:        "none",
+			args:        []string{"file"},
+			expArgs:     []string{"file"},
+			passThrough: true,
+		},
+		{
+			name:   "disabled",
+			args:   []string{"--color=always"},
+			expErr: `babycli: flag "color" is not defined`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			leaf, err := Parse(&Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Name: "wrap",
+					Flags: Flags{
+						{Type: StringFlag, Long: "env", Default: &Default{Value: ""}},
+						{Type: BooleanFlag, Short: "v"},
+					},
+					PassThroughUnknown: tc.passThrough,
+					Function:           func(*Component) Code { return Success },
+				},
+			})
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expPass, leaf.PassThrough())
+			must.Eq(t, tc.expEnv, leaf.GetString("env"))
+			must.Eq(t, tc.expVerbose, leaf.GetBool("v"))
+			if tc.expArgs != nil {
+				must.Eq(t, tc.expArgs, leaf.Arguments())
+			}
+		})
+	}
+}
+
+func TestRun_validateArgs(t *testing.T) {
+	t.Parallel()
+
+	errExtension := errors.New("only .yaml files can be applied")
+
+	cases := []struct {
+		name      string
+		args      []string
+		expCode   Code
+		expStdout string
+		expStderr string
+	}{
+		{
+			name:      "valid",
+			args:      []string{"apply", "a.yaml", "b.yaml"},
+			expCode:   Success,
+			expStdout: "a.yaml b.yaml\n",
+		},
+		{
+			name:      "invalid",
+			args:      []string{"apply", "a.yaml", "b.json"},
+			expCode:   Failure,
+			expStderr: "babycli: only .yaml files can be applied\nUSAGE: tool apply [arguments...]\nRun 'tool apply --help' for more information.\n",
+		},
+		{
+			name:      "help",
+			args:      []string{"apply", "--help", "b.json"},
+			expCode:   Success,
+			expStdout: "NAME:\n  apply\n\nUSAGE:\n  tool apply [arguments...]\n\nGLOBALS:\n--help/-h   boolean - print help message\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Stdout:    stdout,
+				Stderr:    stderr,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "apply",
+							ValidateArgs: func(args []string) error {
+								for _, arg := range args {
+									if !strings.HasSuffix(arg, ".yaml") {
+										return errExtension
+									}
+								}
+								return nil
+							},
+							Function: func(c *Component) Code {
+								write(c.Stdout(), strings.Join(c.Arguments(), " "))
+								return Success
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expStdout, stdout.String())
+			must.Eq(t, tc.expStderr, stderr.String())
+		})
+	}
+
+	_, err := Parse(&Configuration{
+		Arguments: []string{"x"},
+		Top: &Component{
+			ValidateArgs: func([]string) error { return errExtension },
+			Function:     func(*Component) Code { return Success },
+		},
+	})
+	must.ErrorIs(t, err, ErrInvalidArguments)
+	must.ErrorIs(t, err, errExtension)
+}
+
+func TestRun_runWithoutSubcommand(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "itself",
+			args:    []string{"serve", "--port=80"},
+			expCode: Success,
+			expOut:  "serve []\n",
+		},
+		{
+			name:    "arguments",
+			args:    []string{"serve", "--port", "80", "./site"},
+			expCode: Success,
+			expOut:  "serve [./site]\n",
+		},
+		{
+			name:    "subcommand",
+			args:    []string{"serve", "--port", "80", "debug", "x"},
+			expCode: Success,
+			expOut:  "debug [x]\n",
+		},
+		{
+			name:    "missing flag",
+			args:    []string{"serve", "./site"},
+			expCode: Failure,
+			expOut:  "babycli: no value for integer flag \"port\"\nUSAGE: tool serve --port <integer> [command] [arguments...]\nRun 'tool serve --help' for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			print := func(c *Component) Code {
+				writef(c.Stdout(), "%s %v", c.Name, c.Arguments())
+				return Success
+			}
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name:                 "serve",
+							RunWithoutSubcommand: true,
+							Flags:                Flags{{Type: IntFlag, Long: "port", Require: true}},
+							Function:             print,
+							Components: Components{
+								{Name: "debug", Function: print},
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestRun_fallback(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "command",
+			args:    []string{"list"},
+			expCode: Success,
+			expOut:  "list\n",
+		},
+		{
+			name:    "fallback",
+			args:    []string{"-d", "notes.txt", "more.txt"},
+			expCode: Success,
+			expOut:  "before\nopen [notes.txt more.txt] true\n",
+		},
+		{
+			name:    "help",
+			args:    []string{"help"},
+			expCode: Success,
+			expOut:  "NAME:\n  tool\n\nUSAGE:\n  tool [global options] <command>\n\nCOMMANDS:\n  list - \n  help - print help for a command\n\nGLOBALS:\n-d          boolean - \n--help/-h   boolean - print help message\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Globals:   Flags{{Type: BooleanFlag, Short: "d"}},
+				Top: &Component{
+					Name: "tool",
+					Before: func(c *Component) Code {
+						if c.fallback {
+							write(c.Stdout(), "before")
+						}
+						return Success
+					},
+					Components: Components{
+						{
+							Name: "list",
+							Function: func(c *Component) Code {
+								write(c.Stdout(), "list")
+								return Success
+							},
+						},
+					},
+					Fallback: func(c *Component) Code {
+						writef(c.Stdout(), "open %v %t", c.Arguments(), c.GetBool("d"))
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestConfiguration_OnUnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "known",
+			args:    []string{"db", "migrate"},
+			expCode: Success,
+			expOut:  "migrate\n",
+		},
+		{
+			name:    "unknown",
+			args:    []string{"db", "-v", "backup", "--full", "now", "--", "x"},
+			expCode: 3,
+			expOut:  "db backup [--full now -- x] true\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				OnUnknownCommand: func(c *Component, name string, rest []string) Code {
+					writef(c.Stdout(), "%s %s %v %t", c.Name, name, rest, c.GetBool("v"))
+					return 3
+				},
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name:  "db",
+							Flags: Flags{{Type: BooleanFlag, Short: "v", Default: &Default{Value: false}}},
+							Components: Components{
+								{
+									Name: "migrate",
+									Function: func(c *Component) Code {
+										write(c.Stdout(), "migrate")
+										return Success
+									},
+								},
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestRun_usability_unmatched(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		args   []string
+		expOut string
+	}{
+		{
+			name:   "fallback",
+			args:   []string{"notes.txt"},
+			expOut: "fallback\n",
+		},
+		{
+			name:   "delegate",
+			args:   []string{"db", "backup"},
+			expOut: "delegate\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Stdout:    stdout,
+				Stderr:    stderr,
+				OnUnknownCommand: func(c *Component, _ string, _ []string) Code {
+					write(c.Stdout(), "delegate")
+					return Usability
+				},
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "db",
+							Components: Components{
+								{Name: "migrate", Function: func(*Component) Code { return Success }},
+							},
+						},
+					},
+					Fallback: func(c *Component) Code {
+						write(c.Stdout(), "fallback")
+						return Usability
+					},
+				},
+			}).Run()
+			must.Eq(t, Failure, code)
+			must.Eq(t, tc.expOut, stdout.String())
+			must.StrContains(t, stderr.String(), "USAGE:")
+		})
+	}
+}
+
+func TestRun_resolve(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "static",
+			args:    []string{"list"},
+			expCode: Success,
+			expOut:  "list\n",
+		},
+		{
+			name:    "resolved",
+			args:    []string{"plugin-lint", "--fix", "a.go"},
+			expCode: Success,
+			expOut:  "plugin-lint [a.go] true\n",
+		},
+		{
+			name:    "help",
+			args:    []string{"help", "plugin-lint"},
+			expCode: Success,
+			expOut:  "NAME:\n  plugin-lint - run lint\n\nUSAGE:\n  tool plugin-lint [options] [arguments...]\n\nOPTIONS:\n--fix   boolean - \n\nGLOBALS:\n--help/-h   boolean - print help message\n",
+		},
+		{
+			name:    "unresolved",
+			args:    []string{"lint"},
+			expCode: Failure,
+			expOut:  "babycli: subcommand \"lint\" is not defined\nUSAGE: tool <command>\nRun 'tool --help' for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "list",
+							Function: func(c *Component) Code {
+								write(c.Stdout(), "list")
+								return Success
+							},
+						},
+					},
+					Resolve: func(name string) *Component {
+						tool, ok := strings.CutPrefix(name, "plugin-")
+						if !ok {
+							return nil
+						}
+						return &Component{
+							Help:  "run " + tool,
+							Flags: Flags{{Type: BooleanFlag, Long: "fix"}},
+							Function: func(c *Component) Code {
+								writef(c.Stdout(), "%s %v %t", c.Name, c.Arguments(), c.GetBool("fix"))
+								return Success
+							},
+						}
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestRun_enabled(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "enabled",
+			args:    []string{"list"},
+			expCode: Success,
+			expOut:  "list\n",
+		},
+		{
+			name:    "help",
+			args:    []string{"--help"},
+			expCode: Success,
+			expOut:  "NAME:\n  tool\n\nUSAGE:\n  tool <command>\n\nCOMMANDS:\n  list - \n  help - print help for a command\n\nGLOBALS:\n--help/-h   boolean - print help message\n",
+		},
+		{
+			name:    "disabled",
+			args:    []string{"beta"},
+			expCode: Failure,
+			expOut:  "babycli: subcommand \"beta\" is not available\nUSAGE: tool <command>\nRun 'tool --help' for more information.\n",
+		},
+		{
+			name:    "message",
+			args:    []string{"sync", "now"},
+			expCode: Failure,
+			expOut:  "babycli: sync requires an enterprise license\nUSAGE: tool <command>\nRun 'tool --help' for more information.\n",
+		},
+		{
+			name:    "help topic",
+			args:    []string{"help", "beta"},
+			expCode: Failure,
+			expOut:  "babycli: subcommand \"beta\" is not available\nUSAGE: tool <command>\nRun 'tool --help' for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			disabled := func() bool { return false }
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name:    "list",
+							Enabled: func() bool { return true },
+							Function: func(c *Component) Code {
+								write(c.Stdout(), "list")
+								return Success
+							},
+						},
+						{
+							Name:    "beta",
+							Enabled: disabled,
+							Function: func(c *Component) Code {
+								write(c.Stdout(), "beta")
+								return Success
+							},
+						},
+						{
+							Name:        "sync",
+							Enabled:     disabled,
+							Unavailable: "sync requires an enterprise license",
+							Function: func(c *Component) Code {
+								write(c.Stdout(), "sync")
+								return Success
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestRun_authorize(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "allowed",
+			args:    []string{"users", "list"},
+			expCode: Success,
+			expOut:  "users list\n",
+		},
+		{
+			name:    "denied",
+			args:    []string{"users", "delete"},
+			expCode: Failure,
+			expOut:  "babycli: subcommand \"delete\" is not permitted\nUSAGE: tool users <command>\nRun 'tool users --help' for more information.\n",
+		},
+		{
+			name:    "help",
+			args:    []string{"users", "--help"},
+			expCode: Success,
+			expOut:  "NAME:\n  users\n\nUSAGE:\n  tool users <command>\n\nCOMMANDS:\n  list - \n  help - print help for a command\n\nGLOBALS:\n--help/-h   boolean - print help message\n",
+		},
+		{
+			name:    "help all",
+			args:    []string{"--help-all"},
+			expCode: Success,
+			expOut:  "tool\n  users\n    list\n",
+		},
+		{
+			name:    "fallback",
+			args:    []string{"users", "show"},
+			expCode: Success,
+			expOut:  "fallback show\n",
+		},
+		{
+			name:    "fallback denied",
+			args:    []string{"users", "delete-all"},
+			expCode: Failure,
+			expOut:  "babycli: subcommand \"delete-all\" is not permitted\nUSAGE: tool users <command>\nRun 'tool users --help' for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			function := func(c *Component) Code {
+				write(c.Stdout(), strings.Join(c.path()[1:], " "))
+				return Success
+			}
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Authorize: func(path []string) bool {
+					return !strings.HasPrefix(path[len(path)-1], "delete")
+				},
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "users",
+							Components: Components{
+								{Name: "list", Function: function},
+								{Name: "delete", Function: function},
+							},
+							Fallback: func(c *Component) Code {
+								write(c.Stdout(), "fallback "+strings.Join(c.Arguments(), " "))
+								return Success
+							},
+						},
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestComponents_Merge(t *testing.T) {
+	t.Parallel()
+
+	names := func(cs Components) []string {
+		var result []string
+		for _, c := range cs {
+			result = append(result, c.Name)
+		}
+		return result
+	}
+
+	cases := []struct {
+		name     string
+		other    Components
+		expNames []string
+		expErr   string
+	}{
+		{
+			name:     "disjoint",
+			other:    Components{{Name: "users"}, {Name: "roles"}},
+			expNames: []string{"deploy", "status", "users", "roles"},
+		},
+		{
+			name:     "collision",
+			other:    Components{{Name: "users"}, {Name: "status"}},
+			expNames: []string{"deploy", "status"},
+			expErr:   `babycli: component "status" is already defined`,
+		},
+		{
+			name:     "duplicate",
+			other:    Components{{Name: "users"}, {Name: "users"}, {Name: "deploy"}},
+			expNames: []string{"deploy", "status"},
+			expErr:   "babycli: component \"users\" is already defined\nbabycli: component \"deploy\" is already defined",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cs := Components{{Name: "deploy"}, {Name: "status"}}
+			err := cs.Merge(tc.other)
+			if tc.expErr == "" {
+				must.NoError(t, err)
+			} else {
+				must.EqError(t, err, tc.expErr)
+			}
+			must.Eq(t, tc.expNames, names(cs))
+		})
+	}
+}
+
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return "exit status " + strconv.Itoa(e.code)
+}
+
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+func TestRun_functionE(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		err     error
+		expCode Code
+		expText string
+	}{
+		{
+			name:    "success",
+			err:     nil,
+			expCode: Success,
+			expText: "",
+		},
+		{
+			name:    "error",
+			err:     errors.New("unable to connect"),
+			expCode: Failure,
+			expText: "unable to connect\n",
+		},
+		{
+			name:    "exit code",
+			err:     fmt.Errorf("wrapped: %w", &exitError{code: 4}),
+			expCode: 4,
+			expText: "wrapped: exit status 4\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			failure := new(strings.Builder)
+			config := &Configuration{
+				Output: failure,
+				Top: &Component{
+					FunctionE: func(*Component) error {
+						return tc.err
+					},
+				},
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expCode, result)
+			must.Eq(t, tc.expText, failure.String())
+		})
+	}
+}
+
+func TestRun_helpCommand(t *testing.T) {
+	t.Parallel()
+
+	top := func() *Component {
+		return &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "deploy",
+					Help: "deploy things",
+					Components: Components{
+						{
+							Name:     "canary",
+							Help:     "deploy a canary",
+							Function: func(*Component) Code { return Success },
+						},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expText string
+	}{
+		{
+			name:    "top",
+			args:    []string{"help"},
+			expCode: Success,
+			expText: "NAME:\n  tool\n",
+		},
+		{
+			name:    "nested",
+			args:    []string{"help", "deploy", "canary"},
+			expCode: Success,
+			expText: "NAME:\n  canary - deploy a canary\n",
+		},
+		{
+			name:    "unknown",
+			args:    []string{"help", "deploy", "bogus"},
+			expCode: Failure,
+			expText: `babycli: subcommand "bogus" is not defined`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    w,
+				Top:       top(),
+			}
+			c := New(config)
+			result := c.Run()
+			must.Eq(t, tc.expCode, result)
+			must.StrContains(t, w.String(), tc.expText)
+		})
+	}
+}
+
+func TestHelp_helpCommand(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Components: Components{
+			{Name: "about", Help: "about it"},
+		},
+		state: new(state),
+	}
+
+	text := top.help()
+	must.StrContains(t, text, "COMMANDS:\n  about - about it\n  help  - print help for a command")
+}
+
+func TestComponent_usage(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		cmd     *Component
+		globals Flags
+		exp     string
+	}{
+		{
+			name: "leaf",
+			cmd:  &Component{Name: "tool"},
+			exp:  "tool [arguments...]",
+		},
+		{
+			name: "parent",
+			cmd: &Component{
+				Name:       "tool",
+				Components: Components{{Name: "about"}},
+			},
+			exp: "tool <command>",
+		},
+		{
+			name: "parent with default",
+			cmd: &Component{
+				Name:       "tool",
+				Default:    "about",
+				Components: Components{{Name: "about"}},
+			},
+			exp: "tool [command]",
+		},
+		{
+			name: "flags",
+			cmd: &Component{
+				Name: "tool",
+				Flags: Flags{
+					{Type: StringFlag, Long: "name", Require: true},
+					{Type: IntFlag, Short: "n", Require: true},
+					{Type: BooleanFlag, Long: "force", Require: true},
+					{Type: BooleanFlag, Long: "verbose"},
+				},
+			},
+			exp: "tool --name <string> -n <integer> --force [options] [arguments...]",
+		},
+		{
+			name:    "globals",
+			cmd:     &Component{Name: "tool"},
+			globals: Flags{{Type: StringFlag, Long: "region"}, helpFlag},
+			exp:     "tool [global options] [arguments...]",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cmd.state = &state{globals: tc.globals}
+			must.Eq(t, tc.exp, tc.cmd.usage())
+		})
+	}
+}
+
+func TestHelp_messages(t *testing.T) {
+	t.Parallel()
+
+	messages := Catalog{
+		"NAME":                                  "NOM",
+		"USAGE":                                 "UTILISATION",
+		"COMMANDS":                              "COMMANDES",
+		"GLOBALS":                               "GLOBALES",
+		"print help message":                    "afficher l'aide",
+		"subcommand %q is not defined":          "sous-commande %q non définie",
+		"Run '%s --help' for more information.": "Exécutez '%s --help' pour plus d'informations.",
+	}
+
+	top := func() *Component {
+		return &Component{
+			Name: "outil",
+			Components: Components{
+				{Name: "about", Category: "info"},
+			},
+		}
+	}
+
+	w := new(strings.Builder)
+	c := New(&Configuration{
+		Arguments: []string{"--help"},
+		Output:    w,
+		Messages:  messages,
+		Top:       top(),
+	})
+	must.Eq(t, Success, c.Run())
+	must.StrContains(t, w.String(), "NOM:\n  outil\n")
+	must.StrContains(t, w.String(), "UTILISATION:\n  outil <command>\n")
+	must.StrContains(t, w.String(), "INFO COMMANDES:\n")
+	must.StrContains(t, w.String(), "GLOBALES:\n--help/-h   boolean - afficher l'aide\n")
+
+	w.Reset()
+	c = New(&Configuration{
+		Arguments: []string{"bogus"},
+		Output:    w,
+		Messages:  messages,
+		Top:       top(),
+	})
+	must.Eq(t, Failure, c.Run())
+	must.Eq(t, `babycli: sous-commande "bogus" non définie
+UTILISATION: outil <command>
+Exécutez 'outil --help' pour plus d'informations.
+`, w.String())
+}
+
+func TestHelp_sort(t *testing.T) {
+	t.Parallel()
+
+	top := func() *Component {
+		return &Component{
+			Name: "tool",
+			Components: Components{
+				{Name: "stop"},
+				{Name: "start"},
+			},
+			Flags: Flags{
+				{Type: BooleanFlag, Long: "zebra"},
+				{Type: BooleanFlag, Long: "apple"},
+			},
+		}
+	}
+
+	cases := []struct {
+		name  string
+		order SortOrder
+		exp   []string
+	}{
+		{
+			name:  "declaration",
+			order: DeclarationOrder,
+			exp:   []string{"stop", "start", "zebra", "apple"},
+		},
+		{
+			name:  "alphabetical",
+			order: AlphabeticalOrder,
+			exp:   []string{"help", "start", "stop", "apple", "zebra"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			c := New(&Configuration{
+				Arguments: []string{"--help"},
+				Output:    w,
+				Sort:      tc.order,
+				Top:       top(),
+			})
+			must.Eq(t, Success, c.Run())
+
+			text := w.String()
+			for i := 1; i < len(tc.exp); i++ {
+				must.Less(t, strings.Index(text, tc.exp[i]), strings.Index(text, tc.exp[i-1]))
+			}
+		})
+	}
+}
+
+func TestHelp_layout(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		layout HelpLayout
+		exp    string
+	}{
+		{
+			name: "default",
+			exp: `COMMANDS:
+  start                    - start the service
+  restart-all-the-services - restart every service
+  help                     - print help for a command
+
+OPTIONS:
+--dry-run                          boolean - print what would happen
+                                             without doing anything at
+                                             all
+--rate-limit-requests-per-second   integer - limit the rate of requests`,
+		},
+		{
+			name:   "gap",
+			layout: HelpLayout{Gap: 2},
+			exp: `COMMANDS:
+  start                      - start the service
+  restart-all-the-services   - restart every service
+  help                       - print help for a command
+
+OPTIONS:
+--dry-run                            boolean - print what would happen
+                                               without doing anything at
+                                               all
+--rate-limit-requests-per-second     integer - limit the rate of
+                                               requests`,
+		},
+		{
+			name:   "max name width",
+			layout: HelpLayout{MaxNameWidth: 12},
+			exp: `COMMANDS:
+  start - start the service
+  restart-all-the-services
+        - restart every service
+  help  - print help for a command
+
+OPTIONS:
+--dry-run   boolean - print what would happen without doing anything at
+                      all
+--rate-limit-requests-per-second
+            integer - limit the rate of requests`,
+		},
+		{
+			name:   "truncate",
+			layout: HelpLayout{MaxNameWidth: 12, Truncate: true},
+			exp: `COMMANDS:
+  start - start the service
+  restart-all-the-services
+        - restart every service
+  help  - print help for a command
+
+OPTIONS:
+--dry-run   boolean - print what would happen without doing anything ...
+--rate-limit-requests-per-second
+            integer - limit the rate of requests`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := new(strings.Builder)
+			must.Zero(t, New(&Configuration{
+				Arguments:  []string{"--help"},
+				Output:     w,
+				Width:      72,
+				HelpLayout: tc.layout,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{Name: "start", Help: "start the service"},
+						{Name: "restart-all-the-services", Help: "restart every service"},
+					},
+					Flags: Flags{
+						{Type: BooleanFlag, Long: "dry-run", Help: "print what would happen without doing anything at all"},
+						{Type: IntFlag, Long: "rate-limit-requests-per-second", Help: "limit the rate of requests"},
+					},
+				},
+			}).Run())
+
+			text := w.String()
+			start := strings.Index(text, "COMMANDS:")
+			end := strings.Index(text, "\n\nGLOBALS:")
+			must.Eq(t, tc.exp, text[start:end])
+		})
+	}
+}
+
+func TestConfiguration_WarnOutput(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		messages Catalog
+		expOut   string
+		expWarn  string
+	}{
+		{
+			name:   "current",
+			args:   []string{"push", "--force"},
+			expOut: "push true\n",
+		},
+		{
+			name:    "deprecated flag",
+			args:    []string{"push", "--force-push", "--force-push"},
+			expOut:  "push true\n",
+			expWarn: "warning: flag \"force-push\" is deprecated: use --force\n",
+		},
+		{
+			name:    "deprecated command",
+			args:    []string{"upload"},
+			expOut:  "push false\n",
+			expWarn: "warning: command \"upload\" is deprecated: use push\nwarning: uploading is slow\n",
+		},
+		{
+			name: "translated deprecated flag",
+			args: []string{"push", "--force-push"},
+			messages: Catalog{
+				"warning: ":                 "attention : ",
+				"flag %q is deprecated: %s": "l'option %q est obsolète : %s",
+			},
+			expOut:  "push true\n",
+			expWarn: "attention : l'option \"force-push\" est obsolète : use --force\n",
+		},
+		{
+			name: "translated deprecated command",
+			args: []string{"upload"},
+			messages: Catalog{
+				"warning: ":                    "attention : ",
+				"command %q is deprecated: %s": "la commande %q est obsolète : %s",
+				"uploading is slow":            "l'envoi est lent",
+			},
+			expOut:  "push false\n",
+			expWarn: "attention : la commande \"upload\" est obsolète : use push\nattention : l'envoi est lent\n",
+		},
+		{
+			name:   "help",
+			args:   []string{"--help"},
+			expOut: "NAME:\n  tool\n\nUSAGE:\n  tool <command>\n\nCOMMANDS:\n  push - \n  help - print help for a command\n\nGLOBALS:\n--help/-h   boolean - print help message\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			push := func(c *Component) Code {
+				if c.Name == "upload" {
+					c.Warnf("uploading is slow")
+				}
+				c.Printf("push %t\n", c.GetBool("force") || c.Count("force-push") > 0)
+				return Success
+			}
+			flags := Flags{
+				{Type: BooleanFlag, Long: "force", Short: "f", Default: &Default{Value: false}},
+				{Type: BooleanFlag, Long: "force-push", Repeats: true, Deprecated: "use --force", Default: &Default{Value: false}},
+			}
+
+			stdout, warn := new(strings.Builder), new(strings.Builder)
+			code := New(&Configuration{
+				Arguments:  tc.args,
+				Stdout:     stdout,
+				Stderr:     io.Discard,
+				WarnOutput: warn,
+				Messages:   tc.messages,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{Name: "push", Flags: flags, Function: push},
+						{Name: "upload", Deprecated: "use push", Flags: flags, Function: push},
+					},
+				},
+			}).Run()
+			must.Zero(t, code)
+			must.Eq(t, tc.expOut, stdout.String())
+			must.Eq(t, tc.expWarn, warn.String())
+		})
+	}
+}
+
+func TestConfiguration_streams(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		args      []string
+		expCode   Code
+		expStdout string
+		expStderr string
+	}{
+		{
+			name:      "command",
+			args:      []string{"greet"},
+			expCode:   Success,
+			expStdout: "hello\n",
+			expStderr: "careful\n",
+		},
+		{
+			name:      "help requested",
+			args:      []string{"--help"},
+			expCode:   Success,
+			expStdout: "NAME:\n  tool",
+			expStderr: "",
+		},
+		{
+			name:      "help on usage error",
+			args:      nil,
+			expCode:   Failure,
+			expStdout: "",
+			expStderr: "NAME:\n  tool",
+		},
+		{
+			name:      "parse error",
+			args:      []string{"--bogus"},
+			expCode:   Failure,
+			expStdout: "",
+			expStderr: `babycli: flag "bogus" is not defined`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Stdout:    stdout,
+				Stderr:    stderr,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "greet",
+							Function: func(c *Component) Code {
+								c.Printf("%s\n", "hello")
+								c.Errorf("%s\n", "careful")
+								return Success
+							},
+						},
+					},
+				},
+			}
+			result := New(config).Run()
+			must.Eq(t, tc.expCode, result)
+			if tc.expStdout == "" {
+				must.Eq(t, "", stdout.String())
+			}
+			if tc.expStderr == "" {
+				must.Eq(t, "", stderr.String())
+			}
+			must.StrHasPrefix(t, tc.expStdout, stdout.String())
+			must.StrHasPrefix(t, tc.expStderr, stderr.String())
+		})
+	}
+}
+
+func TestRunnable_Parse_leaf(t *testing.T) {
+	t.Parallel()
+
+	var ran bool
+	config := &Configuration{
+		Arguments: []string{"deploy", "canary", "--percent", "10", "one", "two"},
+		Top: &Component{
+			Components: Components{
+				{
+					Name: "deploy",
+					Components: Components{
+						{
+							Name:  "canary",
+							Flags: Flags{{Type: IntFlag, Long: "percent"}},
+							Function: func(*Component) Code {
+								ran = true
+								return Success
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	r := New(config)
+	leaf, err := r.Parse()
+	must.NoError(t, err)
+	must.False(t, ran)
+	must.Eq(t, []string{"deploy", "canary"}, leaf.path())
+	must.Eq(t, 10, leaf.GetInt("percent"))
+	must.Eq(t, []string{"one", "two"}, leaf.Arguments())
+
+	must.Eq(t, Success, r.Run())
+	must.True(t, ran)
+}
+
+func TestRunnable_HelpText(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Version: "v1.0.0",
+		Width:   80,
+		Top: &Component{
+			Name: "tool",
+			Help: "a useful tool",
+			Components: Components{
+				{
+					Name: "remote",
+					Help: "manage remotes",
+					Components: Components{
+						{
+							Name:     "add",
+							Help:     "add a remote",
+							Flags:    Flags{{Type: StringFlag, Long: "url", Help: "remote url", Require: true}},
+							Function: func(*Component) Code { return Success },
+						},
+					},
+				},
+			},
+		},
+	}
+
+	r := New(config)
+	must.Eq(t, `NAME:
+  add - add a remote
+
+USAGE:
+  tool remote add --url <string> [arguments...]
+
+OPTIONS:
+--url   string - remote url
+
+GLOBALS:
+--help/-h   boolean - print help message`, r.HelpText("remote", "add"))
+	must.StrContains(t, r.HelpText(), "COMMANDS:\n  remote - manage remotes\n")
+}
+
+func TestNew_tree_unmodified(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Components: Components{
+			{
+				Name:     "deploy",
+				Flags:    Flags{{Type: StringFlag, Long: "env"}},
+				Function: func(*Component) Code { return Success },
+			},
+		},
+	}
+
+	config := &Configuration{
+		Arguments:  []string{"deploy", "--env", "prod"},
+		Top:        top,
+		Completion: true,
+		Output:     new(strings.Builder),
+	}
+
+	r := New(config)
+	leaf, err := r.Parse()
+	must.NoError(t, err)
+	must.Eq(t, "prod", leaf.GetString("env"))
+	must.Zero(t, r.Run())
+
+	must.Nil(t, top.state)
+	must.Nil(t, top.Components[0].state)
+	must.Nil(t, top.Components[0].parent)
+	must.Len(t, 1, top.Components)
+}
+
+func TestNew_repeated(t *testing.T) {
+	t.Parallel()
+
+	var seen [][]string
+	config := &Configuration{
+		Output: new(strings.Builder),
+		Top: &Component{
+			Name:  "tool",
+			Flags: Flags{{Type: StringFlag, Long: "tag", Repeats: true}},
+			Function: func(c *Component) Code {
+				seen = append(seen, append(c.GetStrings("tag"), c.Arguments()...))
+				return Success
+			},
+		},
+	}
+
+	for _, args := range [][]string{
+		{"--tag", "a", "--tag", "b", "one"},
+		{"--tag", "c", "two"},
+		{"three"},
+	} {
+		config.Arguments = args
+		must.Zero(t, New(config).Run())
+	}
+
+	must.Eq(t, [][]string{{"a", "b", "one"}, {"c", "two"}, {"three"}}, seen)
 }
 
-func TestComponent_GetBooleans(t *testing.T) {
+func TestNew_concurrent(t *testing.T) {
 	t.Parallel()
 
-	var output string
-	var failure *strings.Builder
-
-	cases := []testCase{
-		{
-			name:    "repeated boolens provided no default",
-			expText: "hello [true false true]",
-			expCode: Success,
-			args:    []string{"--verbose", "true", "--verbose", "false", "--verbose", "true"},
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Repeats: true,
-						Require: false,
-					},
-				},
+	globals := Flags{{Type: IntFlag, Long: "count"}}
+	top := &Component{
+		Name: "tool",
+		Components: Components{
+			{
+				Name:  "echo",
+				Flags: Flags{{Type: StringFlag, Long: "word", Repeats: true}},
 				Function: func(c *Component) Code {
-					verbose := c.GetBools("verbose")
-					output = fmt.Sprintf("hello %v", verbose)
+					c.Printf("%d %s", c.GetInt("count"), strings.Join(c.GetStrings("word"), ","))
 					return Success
 				},
 			},
 		},
+	}
+
+	const runs = 50
+	outputs := make([]*strings.Builder, runs)
+	codes := make([]Code, runs)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		outputs[i] = new(strings.Builder)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = New(&Configuration{
+				Arguments: []string{"--count", strconv.Itoa(i), "echo", "--word", "a", "--word", strconv.Itoa(i)},
+				Globals:   globals,
+				Top:       top,
+				Output:    outputs[i],
+			}).Run()
+		}()
+	}
+	wg.Wait()
+
+	for i := range runs {
+		must.Zero(t, codes[i])
+		must.Eq(t, fmt.Sprintf("%d a,%d", i, i), outputs[i].String())
+	}
+}
+
+func TestRun_persistentFlags(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
 		{
-			name:    "repeated booleans not provided no default not required",
-			expText: "hello []",
-			expCode: Success,
-			args:    nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Repeats: true,
-						Require: false,
-					},
-				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBools("verbose")
-					output = fmt.Sprintf("hello %v", verbose)
-					return Success
-				},
-			},
+			name: "on declaring component",
+			args: []string{"db", "--dsn", "postgres://a", "migrate", "up"},
+			exp:  "postgres://a [up]",
 		},
 		{
-			name:     "repeated booleans not provided no default required",
-			expText:  "",
-			expCode:  Failure,
-			expPanic: `babycli: no value for boolean flag "verbose"`,
-			args:     nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Repeats: true,
-						Require: true,
+			name: "on descendant",
+			args: []string{"db", "migrate", "--dsn", "postgres://b", "up"},
+			exp:  "postgres://b [up]",
+		},
+		{
+			name: "default",
+			args: []string{"db", "migrate"},
+			exp:  "postgres://localhost []",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    output,
+				Top: &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name: "db",
+							Persistent: Flags{
+								{Type: StringFlag, Long: "dsn", Default: &Default{Value: "postgres://localhost"}},
+							},
+							Components: Components{
+								{
+									Name: "migrate",
+									Function: func(c *Component) Code {
+										c.Printf("%s %v", c.GetString("dsn"), c.Arguments())
+										return Success
+									},
+								},
+							},
+						},
 					},
 				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBools("verbose")
-					output = fmt.Sprintf("hello %v", verbose)
-					return Success
+			}
+
+			must.Zero(t, New(config).Run())
+			must.Eq(t, tc.exp, output.String())
+		})
+	}
+}
+
+func TestHelp_persistentFlags(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name:       "db",
+					Persistent: Flags{{Type: StringFlag, Long: "dsn", Help: "database"}},
+					Components: Components{
+						{Name: "migrate", Function: func(*Component) Code { return Success }},
+					},
 				},
 			},
 		},
+	}
+
+	r := New(config)
+	must.StrContains(t, r.HelpText("db"), "OPTIONS:\n--dsn   string - database\n")
+	must.StrContains(t, r.HelpText("db", "migrate"), "USAGE:\n  tool db migrate [global options] [arguments...]")
+	must.StrContains(t, r.HelpText("db", "migrate"), "GLOBALS:\n--dsn        string - database\n")
+}
+
+func TestHelp_parentFlags(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Name:  "tool",
+			Flags: Flags{{Type: BooleanFlag, Long: "verbose", Help: "log more"}},
+			Components: Components{
+				{Name: "deploy", Function: func(*Component) Code { return Success }},
+			},
+		},
+	}
+
+	r := New(config)
+	must.StrContains(t, r.HelpText("deploy"), "USAGE:\n  tool deploy [global options] [arguments...]")
+	must.StrContains(t, r.HelpText("deploy"), "GLOBALS:\n--verbose   boolean - log more\n")
+}
+
+func TestRun_parentFlagAfterSubcommand(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
+		{
+			name: "before subcommand",
+			args: []string{"--verbose", "deploy", "--region", "eu"},
+			exp:  "true eu",
+		},
 		{
-			name:    "repeated booleans not provided with default required",
-			expText: "hello [true]",
-			expCode: Success,
-			args:    nil,
-			root: &Component{
-				Flags: Flags{
-					{
-						Type:    BooleanFlag,
-						Long:    "verbose",
-						Repeats: true,
-						Require: true,
-						Default: &Default{
-							Value: true,
+			name: "after subcommand",
+			args: []string{"deploy", "--region", "eu", "--verbose"},
+			exp:  "true eu",
+		},
+		{
+			name: "not given",
+			args: []string{"deploy", "--region", "eu"},
+			exp:  "false eu",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    output,
+				Top: &Component{
+					Name:  "tool",
+					Flags: Flags{{Type: BooleanFlag, Long: "verbose"}},
+					Components: Components{
+						{
+							Name:  "deploy",
+							Flags: Flags{{Type: StringFlag, Long: "region"}},
+							Function: func(c *Component) Code {
+								c.Printf("%t %s", c.GetBool("verbose"), c.GetString("region"))
+								return Success
+							},
 						},
 					},
 				},
-				Function: func(c *Component) Code {
-					verbose := c.GetBools("verbose")
-					output = fmt.Sprintf("hello %v", verbose)
-					return Success
-				},
+			}
+
+			must.Zero(t, New(config).Run())
+			must.Eq(t, tc.exp, output.String())
+		})
+	}
+}
+
+func TestComponent_Count(t *testing.T) {
+	t.Parallel()
+
+	var counts []int
+	config := &Configuration{
+		Arguments: []string{"-v", "-v", "--tag", "a", "-t", "b", "--wait=1s", "-v"},
+		Globals:   Flags{{Type: BooleanFlag, Long: "verbose", Short: "v", Repeats: true}},
+		Top: &Component{
+			Flags: Flags{
+				{Type: StringFlag, Long: "tag", Short: "t", Repeats: true},
+				{Type: DurationFlag, Long: "wait"},
+				{Type: IntFlag, Long: "size"},
+			},
+			Function: func(c *Component) Code {
+				counts = []int{c.Count("verbose"), c.Count("v"), c.Count("tag"), c.Count("wait"), c.Count("size")}
+				return Success
 			},
 		},
 	}
 
-	for _, tc := range cases {
-		output = ""                    // reset for each case
-		failure = new(strings.Builder) // reset for each case
+	must.Zero(t, New(config).Run())
+	must.Eq(t, []int{3, 3, 2, 1, 0}, counts)
+}
+
+func TestComponent_Changed(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		exp  []bool
+	}{
+		{
+			name: "defaults",
+			args: nil,
+			exp:  []bool{false, false},
+		},
+		{
+			name: "explicit false",
+			args: []string{"--force=false"},
+			exp:  []bool{true, false},
+		},
+		{
+			name: "short name",
+			args: []string{"-r", "us-east"},
+			exp:  []bool{false, true},
+		},
+	}
 
+	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var changed []bool
 			config := &Configuration{
 				Arguments: tc.args,
-				Top:       tc.root,
-				Output:    failure,
+				Top: &Component{
+					Flags: Flags{
+						{Type: BooleanFlag, Long: "force", Default: &Default{Value: false}},
+						{Type: StringFlag, Long: "region", Short: "r", Default: &Default{Value: "us-west"}},
+					},
+					Function: func(c *Component) Code {
+						changed = []bool{c.Changed("force"), c.Changed("r")}
+						return Success
+					},
+				},
 			}
-			c := New(config)
-			result := c.Run()
-			must.Eq(t, tc.expText, output)
-			must.Eq(t, tc.expCode, result)
-			must.Eq(t, tc.expPanic, failure.String())
+
+			must.Zero(t, New(config).Run())
+			must.Eq(t, tc.exp, changed)
 		})
 	}
 }
 
-func TestComponent_maybeSplit(t *testing.T) {
+func TestConfiguration_Duplicates(t *testing.T) {
 	t.Parallel()
 
 	cases := []struct {
-		name string
-		arg  string
-		exp  string
-		push []string
+		name       string
+		duplicates Duplicates
+		exp        string
 	}{
 		{
-			name: "plain",
-			arg:  "-name",
-			exp:  "-name",
-			push: nil,
+			name:       "deferred",
+			duplicates: DuplicatesDeferred,
+			exp:        `babycli: multiple values set for string flag "region"`,
 		},
 		{
-			name: "split",
-			arg:  "-name=bob",
-			exp:  "-name",
-			push: []string{"bob"},
+			name:       "last wins",
+			duplicates: DuplicatesLastWins,
+			exp:        "eu-west [a b]",
 		},
 		{
-			name: "quote",
-			arg:  "'a=b'",
-			exp:  "'a=b'",
-			push: nil,
+			name:       "reject",
+			duplicates: DuplicatesReject,
+			exp:        "babycli: flag \"region\" may only be given once\nUSAGE: [options] [arguments...]\nRun with --help for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := new(strings.Builder)
+			config := &Configuration{
+				Arguments:  []string{"--region", "us-east", "--tag", "a", "--region", "eu-west", "--tag", "b"},
+				Output:     output,
+				Duplicates: tc.duplicates,
+				Top: &Component{
+					Flags: Flags{
+						{Type: StringFlag, Long: "region"},
+						{Type: StringFlag, Long: "tag", Repeats: true},
+					},
+					Function: func(c *Component) Code {
+						c.Printf("%s %v", c.GetString("region"), c.GetStrings("tag"))
+						return Success
+					},
+				},
+			}
+
+			_ = New(config).Run()
+			must.Eq(t, tc.exp, output.String())
+		})
+	}
+}
+
+func TestHelp_occurrences(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Name: "tool",
+			Flags: Flags{
+				{Type: StringFlag, Long: "file", Help: "input", Repeats: true, MinOccurrences: 1, MaxOccurrences: 5},
+				{Type: StringFlag, Long: "tag", Help: "label", Repeats: true, MaxOccurrences: 3},
+			},
+			Function: func(*Component) Code { return Success },
+		},
+	}
+
+	text := New(config).HelpText()
+	must.StrContains(t, text, "input (1 to 5 times)")
+	must.StrContains(t, text, "label (at most 3 times)")
+}
+
+func TestDefault_Func(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		exp      string
+		expCalls int
+	}{
+		{
+			name:     "not given",
+			args:     nil,
+			exp:      "alice",
+			expCalls: 1,
 		},
 		{
-			name: "quote split",
-			arg:  "-name='bob dylan'",
-			exp:  "-name",
-			push: []string{"'bob dylan'"},
+			name:     "given",
+			args:     []string{"--user", "bob"},
+			exp:      "bob",
+			expCalls: 0,
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			c := &Component{
-				args: stacks.Simple[string](),
+			t.Parallel()
+
+			calls := 0
+			output := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    output,
+				Top: &Component{
+					Flags: Flags{
+						{
+							Type: StringFlag,
+							Long: "user",
+							Default: &Default{Func: func() any {
+								calls++
+								return "alice"
+							}},
+						},
+					},
+					Function: func(c *Component) Code {
+						c.Printf("%s", c.GetString("user"))
+						return Success
+					},
+				},
 			}
-			result := c.maybeSplit(tc.arg)
-			must.Eq(t, tc.exp, result)
-			must.Eq(t, c.args.Size(), len(tc.push))
+
+			must.Zero(t, New(config).Run())
+			must.Eq(t, tc.exp, output.String())
+			must.Eq(t, tc.expCalls, calls)
 		})
 	}
 }
 
-func TestGlobal_flag_bool(t *testing.T) {
+func TestDefault_Hidden(t *testing.T) {
 	t.Parallel()
 
-	var output string
-
+	output := new(strings.Builder)
 	config := &Configuration{
+		Arguments: []string{"show"},
+		Output:    output,
 		Top: &Component{
-			Function: func(c *Component) Code {
-				output = fmt.Sprintf("v is %t", c.GetBool("verbose"))
-				return Success
-			},
-		},
-		Globals: Flags{
-			{
-				Long: "verbose",
-				Default: &Default{
-					Value: true,
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "show",
+					Flags: Flags{
+						{Type: StringFlag, Long: "token", Help: "api token", Default: &Default{Value: "s3cr3t", Show: true, Hidden: true}},
+						{Type: StringFlag, Long: "region", Help: "region", Default: &Default{Value: "us-east", Show: true}},
+					},
+					Function: func(c *Component) Code {
+						c.Printf("%s", c.GetString("token"))
+						return Success
+					},
 				},
 			},
 		},
 	}
 
-	c := New(config)
-	result := c.Run()
-	must.Zero(t, result)
-	must.Eq(t, "v is true", output)
+	r := New(config)
+	help := r.HelpText("show")
+	must.StrContains(t, help, "api token\n")
+	must.StrContains(t, help, "region (us-east)")
+	must.StrNotContains(t, help, "s3cr3t")
+
+	spec := new(strings.Builder)
+	must.NoError(t, r.WriteSpec(spec))
+	must.StrNotContains(t, spec.String(), "s3cr3t")
+
+	man := new(strings.Builder)
+	must.NoError(t, GenManTree(man, config.Top))
+	must.StrNotContains(t, man.String(), "s3cr3t")
+
+	must.Zero(t, r.Run())
+	must.Eq(t, "s3cr3t", output.String())
 }
 
-func TestGlobal_flag_string(t *testing.T) {
+func TestFlag_Placeholder(t *testing.T) {
 	t.Parallel()
 
-	var output string
-
 	config := &Configuration{
 		Top: &Component{
-			Function: func(c *Component) Code {
-				output = "name is " + c.GetString("name")
-				return Success
-			},
-		},
-		Globals: Flags{
-			{
-				Long: "name",
-				Default: &Default{
-					Value: "billy",
-				},
+			Name: "tool",
+			Flags: Flags{
+				{Type: StringFlag, Long: "file", Help: "input file", Require: true, Placeholder: "PATH"},
+				{Type: IntFlag, Long: "jobs", Help: "parallel jobs"},
 			},
+			Function: func(*Component) Code { return Success },
 		},
 	}
 
-	c := New(config)
-	result := c.Run()
-	must.Zero(t, result)
-	must.Eq(t, "name is billy", output)
+	text := New(config).HelpText()
+	must.StrContains(t, text, "USAGE:\n  tool --file <PATH> [options] [arguments...]")
+	must.StrContains(t, text, "--file      PATH - input file")
+	must.StrContains(t, text, "--jobs   integer - parallel jobs")
 }
 
-func TestGlobal_flag_int(t *testing.T) {
+func TestFlag_Transform(t *testing.T) {
 	t.Parallel()
 
-	var output string
+	lower := func(s string) (string, error) {
+		return strings.ToLower(strings.TrimSpace(s)), nil
+	}
 
-	config := &Configuration{
-		Top: &Component{
-			Function: func(c *Component) Code {
-				output = fmt.Sprintf("age is %d", c.GetInt("age"))
-				return Success
-			},
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "string",
+			args:    []string{"--env", " PROD "},
+			expCode: Success,
+			expOut:  "prod 0 false\n",
 		},
-		Globals: Flags{
-			{
-				Long: "age",
-				Default: &Default{
-					Value: 32,
-				},
-			},
+		{
+			name:    "int",
+			args:    []string{"--port=:8080"},
+			expCode: Success,
+			expOut:  "dev 8080 false\n",
+		},
+		{
+			name:    "boolean",
+			args:    []string{"--force=YES"},
+			expCode: Success,
+			expOut:  "dev 0 true\n",
+		},
+		{
+			name:    "choices",
+			args:    []string{"--env", "Staging"},
+			expCode: Failure,
+			expOut:  "babycli: value \"staging\" for flag \"env\" must be one of [dev|prod]\nUSAGE: tool [options] [arguments...]\nRun 'tool --help' for more information.\n",
+		},
+		{
+			name:    "error",
+			args:    []string{"--port", "8080"},
+			expCode: Failure,
+			expOut:  "babycli: unable to transform value for flag \"port\": missing colon\nUSAGE: tool [options] [arguments...]\nRun 'tool --help' for more information.\n",
 		},
 	}
 
-	c := New(config)
-	result := c.Run()
-	must.Zero(t, result)
-	must.Eq(t, "age is 32", output)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name: "tool",
+					Flags: Flags{
+						{Type: StringFlag, Long: "env", Choices: []string{"dev", "prod"}, Default: &Default{Value: "dev"}, Transform: lower},
+						{
+							Type:    IntFlag,
+							Long:    "port",
+							Default: &Default{Value: 0},
+							Transform: func(s string) (string, error) {
+								port, ok := strings.CutPrefix(s, ":")
+								if !ok {
+									return "", errors.New("missing colon")
+								}
+								return port, nil
+							},
+						},
+						{
+							Type: BooleanFlag,
+							Long: "force",
+							Transform: func(s string) (string, error) {
+								return strconv.FormatBool(s == "YES"), nil
+							},
+						},
+					},
+					Function: func(c *Component) Code {
+						c.Printf("%s %d %t\n", c.GetString("env"), c.GetInt("port"), c.GetBool("force"))
+						return Success
+					},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
 }
 
-func TestGlobal_flag_duration(t *testing.T) {
+func TestFlag_ExpandEnv(t *testing.T) {
 	t.Parallel()
 
-	var output string
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
+		{name: "home", args: []string{"--config", "~/.tool.yaml"}, exp: "/home/alice/.tool.yaml"},
+		{name: "tilde", args: []string{"--config=~"}, exp: "/home/alice"},
+		{name: "user", args: []string{"--config", "~bob/.tool.yaml"}, exp: "~bob/.tool.yaml"},
+		{name: "variable", args: []string{"--config", "$XDG_CONFIG_HOME/tool.yaml"}, exp: "/etc/xdg/tool.yaml"},
+		{name: "braces", args: []string{"--config", "~/${APP}.yaml"}, exp: "/home/alice/tool.yaml"},
+		{name: "unset", args: []string{"--config", "$UNSET/tool.yaml"}, exp: "/tool.yaml"},
+		{name: "disabled", args: []string{"--raw", "~/$APP"}, exp: "~/$APP"},
+	}
 
-	config := &Configuration{
-		Top: &Component{
-			Function: func(c *Component) Code {
-				output = fmt.Sprintf("ttl is %s", c.GetDuration("ttl"))
-				return Success
-			},
-		},
-		Globals: Flags{
-			{
-				Long: "ttl",
-				Default: &Default{
-					Value: 2 * time.Minute,
-				},
-			},
-		},
+	env := map[string]string{
+		"HOME":            "/home/alice",
+		"XDG_CONFIG_HOME": "/etc/xdg",
+		"APP":             "tool",
 	}
 
-	c := New(config)
-	result := c.Run()
-	must.Zero(t, result)
-	must.Eq(t, "ttl is 2m0s", output)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var value string
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Getenv:    func(key string) string { return env[key] },
+				Top: &Component{
+					Flags: Flags{
+						{Type: StringFlag, Long: "config", ExpandEnv: true, Default: &Default{Value: ""}},
+						{Type: StringFlag, Long: "raw", Default: &Default{Value: ""}},
+					},
+					Function: func(c *Component) Code {
+						value = c.GetString("config") + c.GetString("raw")
+						return Success
+					},
+				},
+			}).Run()
+			must.Zero(t, code)
+			must.Eq(t, tc.exp, value)
+		})
+	}
 }
 
-func TestArguments_top(t *testing.T) {
+func TestRun_shortFlagEquals(t *testing.T) {
 	t.Parallel()
 
-	var output string
-
 	cases := []struct {
 		name    string
 		args    []string
-		expText string
+		exp     string
+		expCode Code
 	}{
 		{
-			name:    "none",
-			args:    []string{},
-			expText: "hello []",
+			name: "short int",
+			args: []string{"-n=5", "-f=false", "rest"},
+			exp:  "5 false [rest]",
 		},
 		{
-			name:    "one",
-			args:    []string{"one"},
-			expText: "hello [one]",
+			name: "short bool true",
+			args: []string{"-f=1"},
+			exp:  "0 true []",
 		},
 		{
-			name:    "two",
-			args:    []string{"one", "two"},
-			expText: "hello [one two]",
+			name: "long bool",
+			args: []string{"--force=f", "-n", "2"},
+			exp:  "2 false []",
 		},
 		{
-			name:    "three",
-			args:    []string{"one", "two", "three"},
-			expText: "hello [one two three]",
+			name:    "bad bool",
+			args:    []string{"-f=maybe"},
+			exp:     "babycli: unable to convert value for flag \"force\" to boolean \"maybe\"\n",
+			expCode: Failure,
 		},
 	}
 
 	for _, tc := range cases {
-		output = "" // reset
 		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := new(strings.Builder)
 			config := &Configuration{
 				Arguments: tc.args,
+				Output:    output,
 				Top: &Component{
+					Flags: Flags{
+						{Type: IntFlag, Long: "count", Short: "n"},
+						{Type: BooleanFlag, Long: "force", Short: "f"},
+					},
 					Function: func(c *Component) Code {
-						output = fmt.Sprintf("hello %v", c.Arguments())
+						c.Printf("%d %t %v", c.GetInt("count"), c.GetBool("force"), c.Arguments())
 						return Success
 					},
 				},
 			}
-			c := New(config)
-			result := c.Run()
-			must.Eq(t, tc.expText, output)
-			must.Eq(t, Success, result)
+
+			must.Eq(t, tc.expCode, New(config).Run())
+			must.StrHasPrefix(t, tc.exp, output.String())
 		})
 	}
 }
 
-func TestArguments_child(t *testing.T) {
+func TestRun_attachedDashValues(t *testing.T) {
 	t.Parallel()
 
-	var output string
-
 	cases := []struct {
 		name    string
 		args    []string
-		expText string
+		exp     string
+		expCode Code
 	}{
 		{
-			name:    "none",
-			args:    []string{"child"},
-			expText: "hi []",
+			name: "long negative int",
+			args: []string{"--count=-5"},
+			exp:  "-5 [] 0s",
 		},
 		{
-			name:    "one",
-			args:    []string{"child", "one"},
-			expText: "hi [one]",
+			name: "short negative int",
+			args: []string{"-n=-3"},
+			exp:  "-3 [] 0s",
 		},
 		{
-			name:    "two",
-			args:    []string{"child", "one", "two"},
-			expText: "hi [one two]",
+			name: "dash string",
+			args: []string{"--name=-x"},
+			exp:  "0 [-x] 0s",
 		},
 		{
-			name:    "three",
-			args:    []string{"child", "one", "two", "three"},
-			expText: "hi [one two three]",
+			name: "separated dash strings",
+			args: []string{"--name=-a,-b", "--wait=-1s"},
+			exp:  "0 [-a -b] -1s",
+		},
+		{
+			name:    "detached negative int",
+			args:    []string{"--count", "-5"},
+			exp:     "babycli: no value for integer flag \"count\"\n",
+			expCode: Failure,
 		},
 	}
 
 	for _, tc := range cases {
-		output = "" // reset
 		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := new(strings.Builder)
 			config := &Configuration{
 				Arguments: tc.args,
+				Output:    output,
 				Top: &Component{
-					Components: Components{
-						{
-							Name: "child",
-							Function: func(c *Component) Code {
-								output = fmt.Sprintf("hi %v", c.Arguments())
-								return Success
-							},
-						},
+					Flags: Flags{
+						{Type: IntFlag, Long: "count", Short: "n"},
+						{Type: StringFlag, Long: "name", Repeats: true, Separator: ","},
+						{Type: DurationFlag, Long: "wait"},
+					},
+					Function: func(c *Component) Code {
+						c.Printf("%d %v %s", c.GetInt("count"), c.GetStrings("name"), c.GetDuration("wait"))
+						return Success
 					},
 				},
 			}
-			c := New(config)
-			result := c.Run()
-			must.Eq(t, tc.expText, output)
-			must.Eq(t, Success, result)
+
+			must.Eq(t, tc.expCode, New(config).Run())
+			must.StrHasPrefix(t, tc.exp, output.String())
+		})
+	}
+}
+
+func TestComponent_ExtraArguments(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expArgs  []string
+		expExtra []string
+	}{
+		{
+			name:     "no terminator",
+			args:     []string{"--force", "a", "b"},
+			expArgs:  []string{"a", "b"},
+			expExtra: nil,
+		},
+		{
+			name:     "after flags",
+			args:     []string{"--force", "--", "--color", "-x"},
+			expArgs:  nil,
+			expExtra: []string{"--color", "-x"},
+		},
+		{
+			name:     "after arguments",
+			args:     []string{"a", "--", "b", "--", "c"},
+			expArgs:  []string{"a"},
+			expExtra: []string{"b", "--", "c"},
+		},
+		{
+			name:     "empty",
+			args:     []string{"a", "--"},
+			expArgs:  []string{"a"},
+			expExtra: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var args, extra []string
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Flags: Flags{{Type: BooleanFlag, Long: "force"}},
+					Function: func(c *Component) Code {
+						args = c.Arguments()
+						extra = c.ExtraArguments()
+						return Success
+					},
+				},
+			}
+
+			must.Zero(t, New(config).Run())
+			must.Eq(t, tc.expArgs, args)
+			must.Eq(t, tc.expExtra, extra)
 		})
 	}
 }
+
+func TestFlags_index(t *testing.T) {
+	t.Parallel()
+
+	local := &Flag{Type: StringFlag, Long: "name", Short: "n"}
+	inherited := &Flag{Type: StringFlag, Long: "name"}
+	verbose := &Flag{Type: BooleanFlag, Short: "v"}
+
+	index := Flags{local, inherited, verbose}.index()
+	must.MapLen(t, 3, index)
+	must.Eq(t, local, index["name"])
+	must.Eq(t, local, index["n"])
+	must.Eq(t, verbose, index["v"])
+}
+
+func BenchmarkRunnable_Run_manyFlags(b *testing.B) {
+	var flags Flags
+	var args []string
+	for i := range 50 {
+		name := fmt.Sprintf("option%d", i)
+		flags = append(flags, &Flag{Type: StringFlag, Long: name})
+		args = append(args, "--"+name, "value")
+	}
+	config := &Configuration{
+		Arguments: args,
+		Output:    io.Discard,
+		Top: &Component{
+			Name:  "tool",
+			Flags: flags,
+			Function: func(c *Component) Code {
+				_ = c.GetString("option49")
+				return Success
+			},
+		},
+	}
+
+	b.ReportAllocs()
+	for range b.N {
+		if code := New(config).Run(); code != Success {
+			b.Fatalf("unexpected code %d", code)
+		}
+	}
+}
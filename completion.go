@@ -0,0 +1,314 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// newCompletionComponent creates the built-in completion command.
+func newCompletionComponent() *Component {
+	return &Component{
+		Name:   "completion",
+		Help:   "generate shell completion script",
+		Hidden: true,
+		Flags: Flags{
+			{
+				Type: BooleanFlag,
+				Long: "install",
+				Help: "install the completion script into the shell profile",
+			},
+		},
+		FunctionE: completion,
+	}
+}
+
+// completion prints the completion script for the shell named by the first
+// argument (or $SHELL), or installs it with --install.
+func completion(c *Component) error {
+	shell := filepath.Base(c.Getenv("SHELL"))
+	if c.Nargs() > 0 {
+		shell = c.Arguments()[0]
+	}
+
+	top := c.lineage()[0]
+
+	switch shell {
+	case "fish":
+		if c.GetBool("install") {
+			return installFish(c.getenv, top)
+		}
+		return genFish(c.stdout, top)
+	default:
+		return fmt.Errorf("babycli: completion for shell %q is not supported", shell)
+	}
+}
+
+// installFish adds a line sourcing the completion script to the fish
+// configuration file, unless it is already present. The file is found in
+// $XDG_CONFIG_HOME or $HOME/.config, looked up with getenv.
+func installFish(getenv func(string) string, top *Component) error {
+	dir := getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home := getenv("HOME")
+		if home == "" {
+			return errors.New("babycli: unable to install completion: HOME is not set")
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	profile := filepath.Join(dir, "fish", "config.fish")
+	line := program(top) + " completion fish | source"
+
+	b, err := os.ReadFile(profile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case slices.Contains(strings.Split(string(b), "\n"), line):
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(profile), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	write(f, line)
+	return f.Close()
+}
+
+// GenFishCompletion writes a fish shell completion script for the commands
+// and flags of r.
+func (r *Runnable) GenFishCompletion(w io.Writer) error {
+	return genFish(w, r.root)
+}
+
+// program returns the name of the executable the top component represents.
+func program(top *Component) string {
+	if top.Name != "" {
+		return top.Name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func genFish(w io.Writer, top *Component) error {
+	bw := bufio.NewWriter(w)
+	name := program(top)
+	fn := "__" + strings.ReplaceAll(name, "-", "_")
+
+	writef(bw, "# fish completion for %s", name)
+	writef(bw, "function %s_path", fn)
+	write(bw, "    set -l tokens (commandline -opc)")
+	write(bw, "    set -e tokens[1]")
+	write(bw, "    set -l path")
+	write(bw, "    set -l skip 0")
+	write(bw, "    for token in $tokens")
+	write(bw, "        if test $skip -eq 1")
+	write(bw, "            set skip 0")
+	write(bw, "            continue")
+	write(bw, "        end")
+	write(bw, "        switch $token")
+	if valued := fishValued(top); len(valued) > 0 {
+		writef(bw, "            case %s", strings.Join(valued, " "))
+		write(bw, "                set skip 1")
+	}
+	write(bw, "            case '-*'")
+	write(bw, "            case '*'")
+	write(bw, "                set -a path $token")
+	write(bw, "        end")
+	write(bw, "    end")
+	write(bw, "    echo $path")
+	write(bw, "end")
+	writef(bw, "function %s_using_command", fn)
+	writef(bw, "    set -l path (%s_path)", fn)
+	write(bw, "    test \"$path\" = \"$argv\"")
+	write(bw, "end")
+	writef(bw, "complete -c %s -f", name)
+
+	for _, f := range top.globals.visible() {
+		writef(bw, "complete -c %s%s", name, fishFlag(f))
+	}
+
+	fishComponent(bw, name, fn, nil, top, nil)
+
+	return bw.Flush()
+}
+
+func fishComponent(w io.Writer, name, fn string, path []string, c *Component, inherited Flags) {
+	condition := strings.TrimSpace(fn + "_using_command " + strings.Join(path, " "))
+
+	inherited = slices.Concat(c.Persistent, inherited)
+	for _, f := range slices.Concat(c.Flags, inherited).visible() {
+		writef(w, "complete -c %s -n '%s'%s", name, condition, fishFlag(f))
+	}
+
+	commands := c.allowedComponents()
+	for _, cmd := range commands {
+		writef(w, "complete -c %s -n '%s' -a %s -d '%s'", name, condition, cmd.Name, fishQuote(cmd.Help))
+	}
+
+	if completesFiles(c.Annotations) {
+		writef(w, "complete -c %s -n '%s' -F", name, condition)
+	}
+
+	for _, cmd := range commands {
+		fishComponent(w, name, fn, append(path[:len(path):len(path)], cmd.Name), c.descend(cmd), inherited)
+	}
+}
+
+func fishFlag(f *Flag) string {
+	sb := new(strings.Builder)
+	if f.Long != "" {
+		sb.WriteString(" -l ")
+		sb.WriteString(f.Long)
+	}
+	if f.Short != "" {
+		sb.WriteString(" -s ")
+		sb.WriteString(f.Short)
+	}
+	if f.Type != BooleanFlag {
+		sb.WriteString(" -r")
+	}
+	if completesFiles(f.Annotations) {
+		sb.WriteString(" -F")
+	}
+	if len(f.Choices) > 0 {
+		sb.WriteString(" -a '")
+		sb.WriteString(fishQuote(strings.Join(f.Choices, " ")))
+		sb.WriteString("'")
+	}
+	if f.Help != "" {
+		sb.WriteString(" -d '")
+		sb.WriteString(fishQuote(f.Help))
+		sb.WriteString("'")
+	}
+	return sb.String()
+}
+
+// fishValued returns the names of every flag in the tree that takes a value.
+func fishValued(top *Component) []string {
+	var names []string
+	add := func(fs Flags) {
+		for _, f := range fs {
+			if f.Type == BooleanFlag {
+				continue
+			}
+			if f.Long != "" {
+				names = append(names, "--"+f.Long)
+			}
+			if f.Short != "" {
+				names = append(names, "-"+f.Short)
+			}
+		}
+	}
+
+	add(top.globals)
+	top.Walk(func(_ []string, c *Component) bool {
+		add(c.options())
+		return true
+	})
+
+	return names
+}
+
+// completesFiles returns whether annotations ask for file names to be
+// completed.
+func completesFiles(annotations map[string]string) bool {
+	return annotations["file-completion"] == "true"
+}
+
+// files returns the paths of the entries in the directory partial is in,
+// which is the working directory if partial names none.
+func files(partial string) []string {
+	dir, _ := filepath.Split(partial)
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := dir + entry.Name()
+		if entry.IsDir() {
+			name += string(filepath.Separator)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return strings.ReplaceAll(s, "'", "\\'")
+}
+
+// candidates returns the completions of partial, the word being typed after
+// words on a command line run by c.
+func (c *Component) candidates(words []string, partial string) []string {
+	target := c
+	var pending *Flag
+	for _, word := range words {
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "--":
+			return nil
+		case strings.HasPrefix(word, "-"):
+			name := strings.TrimLeft(word, "-")
+			if flags := target.combine(); !strings.Contains(name, "=") && flags.Contains(name) {
+				if f := flags.Get(name); f.Type != BooleanFlag {
+					pending = f
+				}
+			}
+		case target.Components.Contains(word):
+			target = target.descend(target.Components.Get(word))
+		}
+	}
+
+	var names []string
+	switch {
+	case pending != nil && completesFiles(pending.Annotations):
+		names = slices.Concat(pending.Choices, files(partial))
+	case pending != nil:
+		names = pending.Choices
+	case strings.HasPrefix(partial, "-"):
+		for _, f := range target.combine().visible() {
+			if f.Long != "" {
+				names = append(names, "--"+f.Long)
+			}
+			if f.Short != "" {
+				names = append(names, "-"+f.Short)
+			}
+		}
+	default:
+		for _, cmd := range target.commands() {
+			names = append(names, cmd.Name)
+		}
+		if completesFiles(target.Annotations) {
+			names = append(names, files(partial)...)
+		}
+	}
+
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, partial) && !slices.Contains(matches, name) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,225 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func completionConfig() *Configuration {
+	return &Configuration{
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "deploy",
+					Help: "deploy the app's code",
+					Components: Components{
+						{
+							Name:        "canary",
+							Help:        "deploy a canary",
+							Annotations: map[string]string{"file-completion": "true"},
+							Flags: Flags{
+								{Type: IntFlag, Long: "percent", Short: "p", Help: "percent of traffic"},
+								{Type: StringFlag, Long: "format", Choices: []string{"json", "yaml", "table"}},
+								{Type: StringFlag, Long: "manifest", Annotations: map[string]string{"file-completion": "true"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		Globals: Flags{
+			{Type: StringFlag, Long: "region", Help: "region to use"},
+		},
+	}
+}
+
+func TestRunnable_GenFishCompletion(t *testing.T) {
+	t.Parallel()
+
+	r := New(completionConfig())
+	w := new(strings.Builder)
+	must.NoError(t, r.GenFishCompletion(w))
+
+	script := w.String()
+	must.StrContains(t, script, "            case --region --percent -p --format --manifest\n")
+	must.StrContains(t, script, "complete -c tool -l region -r -d 'region to use'\n")
+	must.StrContains(t, script, "complete -c tool -l help -s h -d 'print help message'\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command' -a deploy -d 'deploy the app\\'s code'\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command deploy' -a canary -d 'deploy a canary'\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command deploy canary' -l percent -s p -r -d 'percent of traffic'\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command deploy canary' -l format -r -a 'json yaml table'\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command deploy canary' -l manifest -r -F\n")
+	must.StrContains(t, script, "complete -c tool -n '__tool_using_command deploy canary' -F\n")
+	must.StrNotContains(t, script, "complete -c tool -n '__tool_using_command deploy' -F\n")
+}
+
+func TestComponent_candidates_files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), nil, 0o644))
+	must.NoError(t, os.Mkdir(filepath.Join(dir, "apps"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644))
+	prefix := dir + string(filepath.Separator)
+
+	cases := []struct {
+		name    string
+		words   []string
+		partial string
+		exp     []string
+	}{
+		{
+			name:    "annotated flag",
+			words:   []string{"deploy", "canary", "--manifest"},
+			partial: prefix + "app",
+			exp:     []string{prefix + "app.yaml", prefix + "apps" + string(filepath.Separator)},
+		},
+		{
+			name:    "plain flag",
+			words:   []string{"deploy", "canary", "--format"},
+			partial: prefix + "app",
+			exp:     nil,
+		},
+		{
+			name:    "annotated arguments",
+			words:   []string{"deploy", "canary"},
+			partial: prefix + "n",
+			exp:     []string{prefix + "notes.txt"},
+		},
+		{
+			name:    "plain arguments",
+			words:   []string{"deploy"},
+			partial: prefix + "n",
+			exp:     nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(completionConfig())
+			must.Eq(t, tc.exp, r.root.candidates(tc.words, tc.partial))
+		})
+	}
+}
+
+func TestRunnable_GenFishCompletion_authorize(t *testing.T) {
+	t.Parallel()
+
+	config := completionConfig()
+	config.Authorize = func(path []string) bool {
+		return path[len(path)-1] != "canary"
+	}
+	w := new(strings.Builder)
+	must.NoError(t, New(config).GenFishCompletion(w))
+
+	script := w.String()
+	must.StrContains(t, script, "-a deploy -d")
+	must.StrNotContains(t, script, "canary")
+}
+
+func TestConfiguration_Completion(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	env := map[string]string{"HOME": home}
+
+	w := new(strings.Builder)
+	run := func(args ...string) Code {
+		config := completionConfig()
+		config.Completion = true
+		config.Output = w
+		config.Arguments = args
+		config.Getenv = func(name string) string { return env[name] }
+		return New(config).Run()
+	}
+
+	must.Eq(t, Success, run("--help"))
+	must.StrNotContains(t, w.String(), "completion")
+
+	must.Eq(t, Success, run("completion", "--install", "fish"))
+	must.Eq(t, Success, run("completion", "--install", "fish"))
+
+	b, err := os.ReadFile(filepath.Join(home, ".config", "fish", "config.fish"))
+	must.NoError(t, err)
+	must.Eq(t, "tool completion fish | source\n", string(b))
+
+	env["XDG_CONFIG_HOME"] = filepath.Join(home, "xdg")
+	must.Eq(t, Success, run("completion", "--install", "fish"))
+	_, err = os.Stat(filepath.Join(home, "xdg", "fish", "config.fish"))
+	must.NoError(t, err)
+
+	delete(env, "XDG_CONFIG_HOME")
+	delete(env, "HOME")
+	must.Eq(t, Failure, run("completion", "--install", "fish"))
+	must.StrContains(t, w.String(), "babycli: unable to install completion: HOME is not set")
+
+	must.Eq(t, Failure, run("completion", "tcsh"))
+	must.StrContains(t, w.String(), `babycli: completion for shell "tcsh" is not supported`)
+}
+
+func TestConfiguration_Completion_skipped(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		top    *Component
+		args   []string
+		expOut string
+	}{
+		{
+			name: "leaf",
+			top: &Component{
+				Name: "tool",
+				Function: func(c *Component) Code {
+					c.Printf("open %v", c.Arguments())
+					return Success
+				},
+			},
+			args:   []string{"file.txt"},
+			expOut: "open [file.txt]",
+		},
+		{
+			name: "defined",
+			top: &Component{
+				Name: "tool",
+				Components: Components{
+					{
+						Name: "completion",
+						Function: func(c *Component) Code {
+							c.Printf("own completion")
+							return Success
+						},
+					},
+				},
+			},
+			args:   []string{"completion"},
+			expOut: "own completion",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Top:        tc.top,
+				Completion: true,
+				Arguments:  tc.args,
+				Output:     out,
+			}).Run()
+			must.Eq(t, Success, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Crash is the exit code returned when a command panics, the same as for a
+// Go program which does not recover from a panic.
+const Crash Code = 2
+
+// PanicError is the error passed to Configuration.ErrorHandler when a command
+// panics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine which panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("babycli: command panicked: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// misuseError is what babycli panics with when it is misused, such as by
+// reading a flag with more than one value, as opposed to a crash.
+type misuseError struct {
+	msg string
+}
+
+func (e *misuseError) Error() string {
+	return e.msg
+}
+
+// misuse returns the error of a panic caused by misusing babycli, or nil if p
+// is any other panic.
+func misuse(p any) error {
+	var err *misuseError
+	if e, ok := p.(error); ok && errors.As(e, &err) {
+		return err
+	}
+	return nil
+}
+
+// crashReport describes the panic of err in the run of r.
+func (r *Runnable) crashReport(err *PanicError) string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "babycli: %s panicked: %v\n", strings.Join(r.resolved().path(), " "), err.Value)
+	if r.root.version != nil {
+		fmt.Fprintf(sb, "version: %s\n", r.root.version)
+	}
+	fmt.Fprintf(sb, "go: %s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	sb.Write(err.Stack)
+	return sb.String()
+}
+
+// crash writes the crash report of err to Stderr, and to a file in the
+// CrashDir, if configured.
+func (r *Runnable) crash(err *PanicError) {
+	report := r.crashReport(err)
+	write(r.output, report)
+
+	if r.crashDir == "" {
+		return
+	}
+
+	f, ferr := os.CreateTemp(r.crashDir, program(r.root)+"-crash-*.txt")
+	if ferr == nil {
+		_, ferr = f.WriteString(report)
+		if cerr := f.Close(); ferr == nil {
+			ferr = cerr
+		}
+	}
+	if ferr != nil {
+		writef(r.output, "babycli: unable to write crash report: %v", ferr)
+		return
+	}
+	writef(r.output, "babycli: crash report written to %s", f.Name())
+}
@@ -0,0 +1,110 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func crashConfig(value any) *Configuration {
+	return &Configuration{
+		Arguments: []string{"deploy"},
+		Version:   "v1.2.3",
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name:     "deploy",
+					Function: func(*Component) Code { panic(value) },
+				},
+			},
+		},
+	}
+}
+
+func TestRun_crash(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		value  any
+		expMsg string
+	}{
+		{name: "string", value: "boom", expMsg: "boom"},
+		{name: "prefixed string", value: "babycli: boom", expMsg: "babycli: boom"},
+		{name: "error", value: errors.New("bad state"), expMsg: "bad state"},
+		{name: "other", value: 42, expMsg: "42"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := new(strings.Builder)
+			config := crashConfig(tc.value)
+			config.Stdout = io.Discard
+			config.Stderr = stderr
+
+			code := New(config).Run()
+			must.Eq(t, Crash, code)
+
+			report := stderr.String()
+			must.StrHasPrefix(t, "babycli: tool deploy panicked: "+tc.expMsg+"\nversion: v1.2.3\ngo: ", report)
+			must.StrContains(t, report, "goroutine ")
+			must.StrContains(t, report, "TestRun_crash")
+		})
+	}
+}
+
+func TestRun_crashDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stderr := new(strings.Builder)
+	config := crashConfig("boom")
+	config.Stderr = stderr
+	config.CrashDir = dir
+	config.ExitCodes = &ExitCodes{Crash: 70}
+
+	code := New(config).Run()
+	must.Eq(t, 70, code)
+
+	files, err := filepath.Glob(filepath.Join(dir, "tool-crash-*.txt"))
+	must.NoError(t, err)
+	must.Len(t, 1, files)
+	must.StrHasSuffix(t, "babycli: crash report written to "+files[0]+"\n", stderr.String())
+
+	b, err := os.ReadFile(files[0])
+	must.NoError(t, err)
+	must.StrHasPrefix(t, "babycli: tool deploy panicked: boom\n", string(b))
+}
+
+func TestRun_crashHandler(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("bad state")
+	var handled error
+	config := crashConfig(cause)
+	config.Output = io.Discard
+	config.ErrorHandler = func(err error) Code {
+		handled = err
+		return 9
+	}
+
+	code := New(config).Run()
+	must.Eq(t, 9, code)
+
+	var perr *PanicError
+	must.True(t, errors.As(handled, &perr))
+	must.ErrorIs(t, handled, cause)
+	must.EqError(t, handled, "babycli: command panicked: bad state")
+	must.StrContains(t, string(perr.Stack), "goroutine ")
+}
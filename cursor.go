@@ -0,0 +1,72 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"slices"
+)
+
+// cursor reads the arguments of a run in order, by an index into them rather
+// than by consuming them, so the arguments left can be looked at any number of
+// times.
+type cursor struct {
+	args []string
+	pos  int
+
+	// extra are the arguments after a "--" terminator read by terminate.
+	extra []string
+}
+
+func (c *cursor) empty() bool {
+	return c.pos >= len(c.args)
+}
+
+// peek returns the next argument without reading it.
+func (c *cursor) peek() string {
+	return c.args[c.pos]
+}
+
+// next reads the next argument.
+func (c *cursor) next() string {
+	arg := c.args[c.pos]
+	c.pos++
+	return arg
+}
+
+// back unreads the argument read last.
+func (c *cursor) back() {
+	c.pos--
+}
+
+// replace substitutes s for the argument read last, to be read next.
+func (c *cursor) replace(s string) {
+	c.pos--
+	c.args[c.pos] = s
+}
+
+// insert places words before the next argument, to be read first.
+func (c *cursor) insert(words []string) {
+	c.args = slices.Concat(c.args[:c.pos], words, c.args[c.pos:])
+}
+
+// terminate ends the arguments at the next one, a "--" terminator, keeping
+// those after it as extra.
+func (c *cursor) terminate() {
+	c.extra = c.args[c.pos+1:]
+	c.args = c.args[:c.pos]
+}
+
+// remaining returns the arguments left up to any "--" terminator, and those
+// after it, or nil extra if there is no terminator.
+func (c *cursor) remaining() (args, extra []string) {
+	args = c.args[c.pos:]
+	extra = c.extra
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, extra = args[:i], args[i+1:]
+	}
+	if len(args) == 0 {
+		args = nil
+	}
+	return slices.Clip(args), extra
+}
@@ -0,0 +1,147 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// newDocsComponent creates the built-in docs command.
+func newDocsComponent() *Component {
+	return &Component{
+		Name:      "docs",
+		Help:      "write the documentation of every command to a directory",
+		Hidden:    true,
+		FunctionE: docs,
+	}
+}
+
+// docs writes the Markdown pages and man page of the command tree into the
+// directory named by the first argument (default "docs").
+func docs(c *Component) error {
+	dir := "docs"
+	if c.Nargs() > 0 {
+		dir = c.Arguments()[0]
+	}
+
+	top := c.lineage()[0]
+	if err := GenMarkdownTree(dir, top); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, program(top)+".1"))
+	if err != nil {
+		return err
+	}
+	if err = GenManTree(f, top); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// GenMarkdownTree writes a Markdown page for top and for every subcommand
+// below it into dir, which is created if missing. Pages are named after the
+// path of their command, like "tool_deploy.md", and link to the pages of their
+// subcommands.
+func GenMarkdownTree(dir string, top *Component) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return markdownTree(dir, []string{program(top)}, top)
+}
+
+func markdownTree(dir string, path []string, c *Component) error {
+	sb := new(strings.Builder)
+	markdown(sb, path, c)
+	page := strings.TrimSpace(sb.String()) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, markdownPage(path)), []byte(page), 0o644); err != nil {
+		return err
+	}
+
+	for _, cmd := range c.Components.visible() {
+		if err := markdownTree(dir, append(path[:len(path):len(path)], cmd.Name), c.descend(cmd)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownPage returns the file name of the page of the command at path.
+func markdownPage(path []string) string {
+	return strings.Join(path, "_") + ".md"
+}
+
+func markdown(w io.Writer, path []string, c *Component) {
+	writef(w, "# %s\n", strings.Join(path, " "))
+
+	if c.Help != "" {
+		writef(w, "%s\n", c.Help)
+	}
+
+	write(w, "## Usage\n")
+	write(w, "```")
+	write(w, strings.Join(append(path[:len(path):len(path)], c.arguments()...), " "))
+	write(w, "```\n")
+
+	if c.Description != "" {
+		write(w, "## Description\n")
+		writef(w, "%s\n", strings.TrimSpace(c.Description))
+	}
+
+	if len(c.Examples) > 0 {
+		write(w, "## Examples\n")
+		for _, example := range c.Examples {
+			if example.Help != "" {
+				writef(w, "%s:\n", example.Help)
+			}
+			write(w, "```")
+			write(w, example.Command)
+			write(w, "```\n")
+		}
+	}
+
+	if len(c.Annotations) > 0 {
+		write(w, "## Annotations\n")
+		for _, key := range slices.Sorted(maps.Keys(c.Annotations)) {
+			writef(w, "- `%s`: %s", key, c.Annotations[key])
+		}
+		write(w, "")
+	}
+
+	if options := c.options().visible(); len(options) > 0 {
+		write(w, "## Options\n")
+		for _, f := range options {
+			parts := f.help(nil)
+			line := fmt.Sprintf("- `%s` *%s*", parts[0], parts[1])
+			if help := strings.TrimSpace(parts[2]); help != "" {
+				line += " - " + help
+			}
+			write(w, line)
+			for _, key := range slices.Sorted(maps.Keys(f.Annotations)) {
+				writef(w, "  - `%s`: %s", key, f.Annotations[key])
+			}
+		}
+		write(w, "")
+	}
+
+	if commands := c.Components.visible(); len(commands) > 0 {
+		write(w, "## Commands\n")
+		for _, cmd := range commands {
+			sub := append(path[:len(path):len(path)], cmd.Name)
+			line := fmt.Sprintf("- [%s](%s)", strings.Join(sub, " "), markdownPage(sub))
+			if cmd.Help != "" {
+				line += " - " + cmd.Help
+			}
+			write(w, line)
+		}
+		write(w, "")
+	}
+}
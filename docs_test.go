@@ -0,0 +1,82 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestConfiguration_Docs(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "site")
+	w := new(strings.Builder)
+	config := completionConfig()
+	config.Docs = true
+	config.Output = w
+	config.Top.Components[0].Examples = []Example{
+		{Help: "deploy a canary to a tenth of traffic", Command: "tool deploy canary -p 10"},
+	}
+	config.Top.Components[0].Annotations = map[string]string{"owner": "platform", "area": "release"}
+	config.Arguments = []string{"docs", dir}
+	must.Eq(t, Success, New(config).Run())
+
+	entries, err := os.ReadDir(dir)
+	must.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	must.Eq(t, []string{"tool.1", "tool.md", "tool_deploy.md", "tool_deploy_canary.md"}, names)
+
+	b, err := os.ReadFile(filepath.Join(dir, "tool_deploy.md"))
+	must.NoError(t, err)
+	must.Eq(t, "# tool deploy\n"+
+		"\n"+
+		"deploy the app's code\n"+
+		"\n"+
+		"## Usage\n"+
+		"\n"+
+		"```\n"+
+		"tool deploy [global options] <command>\n"+
+		"```\n"+
+		"\n"+
+		"## Examples\n"+
+		"\n"+
+		"deploy a canary to a tenth of traffic:\n"+
+		"\n"+
+		"```\n"+
+		"tool deploy canary -p 10\n"+
+		"```\n"+
+		"\n"+
+		"## Annotations\n"+
+		"\n"+
+		"- `area`: release\n"+
+		"- `owner`: platform\n"+
+		"\n"+
+		"## Commands\n"+
+		"\n"+
+		"- [tool deploy canary](tool_deploy_canary.md) - deploy a canary\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "tool_deploy_canary.md"))
+	must.NoError(t, err)
+	must.StrContains(t, string(b), "## Usage\n\n```\ntool deploy canary [global options] [options] [arguments...]\n```\n")
+	must.StrContains(t, string(b), "## Options\n\n"+
+		"- `--percent/-p` *integer* - percent of traffic\n"+
+		"- `--format` *string* - [json|yaml|table]\n"+
+		"- `--manifest` *string*\n"+
+		"  - `file-completion`: true\n")
+
+	b, err = os.ReadFile(filepath.Join(dir, "tool.1"))
+	must.NoError(t, err)
+	must.StrContains(t, string(b), ".SS \"tool deploy canary\"\n")
+
+	must.Eq(t, Success, New(&Configuration{Top: config.Top, Docs: true, Output: w, Arguments: []string{"--help"}}).Run())
+	must.StrNotContains(t, w.String(), "docs")
+}
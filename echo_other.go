@@ -0,0 +1,19 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package babycli
+
+import (
+	"errors"
+	"os"
+)
+
+func disableEcho(*os.File) (func(), error) {
+	return nil, errors.New("babycli: disabling echo is not supported")
+}
+
+func rawMode(*os.File) (func(), error) {
+	return nil, errors.New("babycli: raw terminal mode is not supported")
+}
@@ -0,0 +1,62 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package babycli
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func termios(f *os.File, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// disableEcho turns off echo of the terminal f, returning a function which
+// restores the previous state.
+func disableEcho(f *os.File) (func(), error) {
+	var original syscall.Termios
+	if err := termios(f, getTermios, &original); err != nil {
+		return nil, err
+	}
+
+	silent := original
+	silent.Lflag &^= syscall.ECHO
+	if err := termios(f, setTermios, &silent); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = termios(f, setTermios, &original)
+	}, nil
+}
+
+// rawMode turns off line buffering, echo, and signal keys of the terminal f,
+// so input can be read one key at a time. It returns a function which
+// restores the previous state.
+func rawMode(f *os.File) (func(), error) {
+	var original syscall.Termios
+	if err := termios(f, getTermios, &original); err != nil {
+		return nil, err
+	}
+
+	raw := original
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termios(f, setTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = termios(f, setTermios, &original)
+	}, nil
+}
@@ -0,0 +1,335 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyBackspace = 8
+	keyTab       = 9
+	keyCtrlK     = 11
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// editor is a LineReader for terminals, with history, reverse search with ^R,
+// and completion with tab.
+type editor struct {
+	in       io.RuneReader
+	out      io.Writer
+	terminal *os.File
+	history  []string
+	complete func(line string) []string
+}
+
+func newEditor(terminal *os.File, out io.Writer, complete func(string) []string) *editor {
+	return &editor{
+		in:       unbuffered{terminal},
+		out:      out,
+		terminal: terminal,
+		complete: complete,
+	}
+}
+
+// line is the state of a line being edited.
+type line struct {
+	prompt string
+	buf    []rune
+	pos    int
+
+	// index is the position in history being shown, and draft the line
+	// being typed before moving through history.
+	index int
+	draft []rune
+}
+
+func (l *line) insert(rs ...rune) {
+	l.buf = slices.Insert(l.buf, l.pos, rs...)
+	l.pos += len(rs)
+}
+
+func (l *line) set(s string) {
+	l.buf = []rune(s)
+	l.pos = len(l.buf)
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	if e.terminal != nil {
+		if restore, err := rawMode(e.terminal); err == nil {
+			defer restore()
+		}
+	}
+
+	l := &line{prompt: prompt, index: len(e.history)}
+	e.redraw(l)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			e.write("\r\n")
+			s := string(l.buf)
+			e.remember(s)
+			return s, nil
+		case keyCtrlC:
+			e.write("^C\r\n")
+			return "", nil
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			if l.pos < len(l.buf) {
+				l.buf = slices.Delete(l.buf, l.pos, l.pos+1)
+			}
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			l.pos = max(l.pos-1, 0)
+		case keyCtrlF:
+			l.pos = min(l.pos+1, len(l.buf))
+		case keyCtrlP:
+			e.previous(l)
+		case keyCtrlN:
+			e.next(l)
+		case keyBackspace, keyDelete:
+			if l.pos > 0 {
+				l.buf = slices.Delete(l.buf, l.pos-1, l.pos)
+				l.pos--
+			}
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.buf = slices.Delete(l.buf, 0, l.pos)
+			l.pos = 0
+		case keyCtrlW:
+			start := l.pos
+			for start > 0 && l.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && l.buf[start-1] != ' ' {
+				start--
+			}
+			l.buf = slices.Delete(l.buf, start, l.pos)
+			l.pos = start
+		case keyCtrlR:
+			if s, submit := e.search(l); submit {
+				e.write("\r\n")
+				e.remember(s)
+				return s, nil
+			}
+		case keyTab:
+			e.tab(l)
+		case keyEscape:
+			e.escape(l)
+		default:
+			if unicode.IsPrint(r) {
+				l.insert(r)
+			}
+		}
+
+		e.redraw(l)
+	}
+}
+
+// escape handles the escape sequences sent by arrow and movement keys.
+func (e *editor) escape(l *line) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return
+	}
+
+	switch r {
+	case 'A':
+		e.previous(l)
+	case 'B':
+		e.next(l)
+	case 'C':
+		l.pos = min(l.pos+1, len(l.buf))
+	case 'D':
+		l.pos = max(l.pos-1, 0)
+	case 'H':
+		l.pos = 0
+	case 'F':
+		l.pos = len(l.buf)
+	case '3':
+		if next, _, err := e.in.ReadRune(); err == nil && next == '~' && l.pos < len(l.buf) {
+			l.buf = slices.Delete(l.buf, l.pos, l.pos+1)
+		}
+	}
+}
+
+func (e *editor) previous(l *line) {
+	if l.index == 0 {
+		return
+	}
+	if l.index == len(e.history) {
+		l.draft = slices.Clone(l.buf)
+	}
+	l.index--
+	l.set(e.history[l.index])
+}
+
+func (e *editor) next(l *line) {
+	if l.index == len(e.history) {
+		return
+	}
+	l.index++
+	if l.index == len(e.history) {
+		l.set(string(l.draft))
+		return
+	}
+	l.set(e.history[l.index])
+}
+
+// search finds earlier lines containing the text typed, newest first, until
+// the line found is accepted into l, or submitted with enter.
+func (e *editor) search(l *line) (string, bool) {
+	var query []rune
+	match := ""
+	index := len(e.history)
+
+	find := func(from int) {
+		for i := from; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				index, match = i, e.history[i]
+				return
+			}
+		}
+	}
+
+	for {
+		e.write(fmt.Sprintf("\r(reverse-i-search)`%s': %s\x1b[K", string(query), match))
+
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", false
+		}
+
+		switch {
+		case r == '\r' || r == '\n':
+			return match, true
+		case r == keyCtrlR:
+			find(index - 1)
+		case r == keyBackspace || r == keyDelete:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(e.history) - 1)
+			}
+		case r == keyCtrlG || r == keyCtrlC:
+			return "", false
+		case unicode.IsPrint(r):
+			query = append(query, r)
+			find(min(index, len(e.history)-1))
+		default:
+			if match != "" {
+				l.set(match)
+				l.index = index
+			}
+			return "", false
+		}
+	}
+}
+
+// tab completes the word before the cursor, or lists the completions if more
+// than one is possible.
+func (e *editor) tab(l *line) {
+	if e.complete == nil {
+		return
+	}
+
+	start := l.pos
+	for start > 0 && l.buf[start-1] != ' ' {
+		start--
+	}
+	partial := string(l.buf[start:l.pos])
+
+	candidates := e.complete(string(l.buf[:l.pos]))
+	switch len(candidates) {
+	case 0:
+	case 1:
+		l.buf = slices.Delete(l.buf, start, l.pos)
+		l.pos = start
+		l.insert([]rune(candidates[0] + " ")...)
+	default:
+		prefix := commonPrefix(candidates)
+		if len(prefix) > len(partial) {
+			l.buf = slices.Delete(l.buf, start, l.pos)
+			l.pos = start
+			l.insert([]rune(prefix)...)
+			return
+		}
+		e.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+	}
+}
+
+// commonPrefix returns the longest run of runes every word starts with.
+func commonPrefix(words []string) string {
+	prefix := []rune(words[0])
+	for _, word := range words[1:] {
+		n := 0
+		for _, r := range word {
+			if n == len(prefix) || prefix[n] != r {
+				break
+			}
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+// remember adds s to the history, unless it is empty or repeats the last line.
+func (e *editor) remember(s string) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == s {
+		return
+	}
+	e.history = append(e.history, s)
+}
+
+// redraw writes the prompt and the line, and moves the cursor into place.
+func (e *editor) redraw(l *line) {
+	s := "\r" + l.prompt + string(l.buf) + "\x1b[K"
+	if back := len(l.buf) - l.pos; back > 0 {
+		s += fmt.Sprintf("\x1b[%dD", back)
+	}
+	e.write(s)
+}
+
+func (e *editor) write(s string) {
+	_, _ = io.WriteString(e.out, s)
+}
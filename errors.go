@@ -0,0 +1,130 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownFlag    = errors.New("unknown flag")
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrMissingValue   = errors.New("missing value")
+	ErrBadValue       = errors.New("bad value")
+	ErrRepeatedFlag   = errors.New("repeated flag")
+
+	ErrInvalidArguments   = errors.New("invalid arguments")
+	ErrUnavailableCommand = errors.New("unavailable command")
+	ErrForbiddenCommand   = errors.New("forbidden command")
+)
+
+// ParseError is returned when the command line arguments do not match the
+// component specification. It wraps one of the Err sentinel values.
+type ParseError struct {
+	Err     error
+	Message string
+
+	format    string
+	args      []any
+	component *Component
+
+	// errs are the errors combined into e by joinParse.
+	errs []*ParseError
+}
+
+func (e *ParseError) Error() string {
+	return "babycli: " + e.Message
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// localize returns the message of e translated by the catalog of s.
+func (e *ParseError) localize(s *style) string {
+	return "babycli: " + e.text(s)
+}
+
+func (e *ParseError) text(s *style) string {
+	switch {
+	case len(e.errs) > 0:
+		texts := make([]string, 0, len(e.errs))
+		for _, err := range e.errs {
+			texts = append(texts, err.text(s))
+		}
+		return strings.Join(texts, "; ")
+	case e.format == "":
+		return e.Message
+	default:
+		return fmt.Sprintf(s.text(e.format), e.args...)
+	}
+}
+
+func parsef(err error, msg string, args ...any) *ParseError {
+	return &ParseError{
+		Err:     err,
+		Message: fmt.Sprintf(msg, args...),
+		format:  msg,
+		args:    args,
+	}
+}
+
+// joinParse combines errs into one *ParseError, which matches each of their
+// Err sentinel values.
+func joinParse(errs []*ParseError) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	sentinels := make([]error, 0, len(errs))
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		sentinels = append(sentinels, err.Err)
+		messages = append(messages, err.Message)
+	}
+
+	return &ParseError{
+		Err:     errors.Join(sentinels...),
+		Message: strings.Join(messages, "; "),
+		errs:    errs,
+	}
+}
+
+// usageError marks an error as caused by invalid use of a command.
+type usageError struct {
+	err error
+}
+
+// UsageError marks err, returned by a FunctionE, as caused by invalid use of
+// the command, such as a missing argument. Like a ParseError, it is reported
+// with the help of the command and the exit code for usage errors. It
+// returns nil if err is nil.
+func UsageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err: err}
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// attach records c as the component on which parsing failed.
+func (c *Component) attach(err error) error {
+	var perr *ParseError
+	if errors.As(err, &perr) && perr.component == nil {
+		perr.component = c
+	}
+	return err
+}
@@ -0,0 +1,414 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRunnable_Parse(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    error
+		expMsg string
+	}{
+		{
+			name: "ok",
+			args: []string{"child", "--count", "3"},
+			exp:  nil,
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"child", "--size", "3"},
+			exp:    ErrUnknownFlag,
+			expMsg: `babycli: flag "size" is not defined`,
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"other"},
+			exp:    ErrUnknownCommand,
+			expMsg: `babycli: subcommand "other" is not defined`,
+		},
+		{
+			name:   "missing value",
+			args:   []string{"child", "--count"},
+			exp:    ErrMissingValue,
+			expMsg: `babycli: no value for integer flag "count"`,
+		},
+		{
+			name:   "bad choice",
+			args:   []string{"child", "--format", "xml"},
+			exp:    ErrBadValue,
+			expMsg: `babycli: value "xml" for flag "format" must be one of [json|yaml]`,
+		},
+		{
+			name:   "bad value",
+			args:   []string{"child", "--count", "three"},
+			exp:    ErrBadValue,
+			expMsg: `babycli: unable to convert value for flag "count" to int "three"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Components: Components{
+						{
+							Name: "child",
+							Flags: Flags{
+								{
+									Type: IntFlag,
+									Long: "count",
+								},
+								{
+									Type:    StringFlag,
+									Long:    "format",
+									Choices: []string{"json", "yaml"},
+								},
+							},
+							Function: func(*Component) Code {
+								return Success
+							},
+						},
+					},
+				},
+			}
+			r := New(config)
+			leaf, err := r.Parse()
+			if tc.exp == nil {
+				must.NoError(t, err)
+				must.Eq(t, "child", leaf.Name)
+				must.Eq(t, 3, leaf.GetInt("count"))
+				return
+			}
+			must.ErrorIs(t, err, tc.exp)
+			must.EqError(t, err, tc.expMsg)
+		})
+	}
+}
+
+func TestConfiguration_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		args   []string
+		expErr string
+	}{
+		{
+			name:   "parse error",
+			args:   []string{"--bogus"},
+			expErr: `babycli: flag "bogus" is not defined`,
+		},
+		{
+			name:   "runtime error",
+			args:   []string{"--fail"},
+			expErr: "it failed",
+		},
+		{
+			name:   "panic",
+			args:   []string{"--name", "a", "--name", "b"},
+			expErr: `babycli: multiple values set for string flag "name"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled error
+			failure := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    failure,
+				ErrorHandler: func(err error) Code {
+					handled = err
+					return 42
+				},
+				Top: &Component{
+					Flags: Flags{
+						{Type: BooleanFlag, Long: "fail"},
+						{Type: StringFlag, Long: "name"},
+					},
+					FunctionE: func(c *Component) error {
+						_ = c.GetString("name")
+						if c.GetBool("fail") {
+							return errors.New("it failed")
+						}
+						return nil
+					},
+				},
+			}
+			r := New(config)
+			result := r.Run()
+			must.Eq(t, 42, result)
+			must.EqError(t, handled, tc.expErr)
+			must.Eq(t, "", failure.String())
+		})
+	}
+}
+
+func TestRunnable_Run_usage_error(t *testing.T) {
+	t.Parallel()
+
+	failure := new(strings.Builder)
+	config := &Configuration{
+		Arguments: []string{"deploy", "--bogus"},
+		Output:    failure,
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name:     "deploy",
+					Function: func(*Component) Code { return Success },
+				},
+			},
+		},
+	}
+
+	r := New(config)
+	result := r.Run()
+	must.Eq(t, Failure, result)
+	must.Eq(t, `babycli: flag "bogus" is not defined
+USAGE: tool deploy [arguments...]
+Run 'tool deploy --help' for more information.
+`, failure.String())
+}
+
+func TestConfiguration_ExitCodes(t *testing.T) {
+	t.Parallel()
+
+	codes := &ExitCodes{
+		Usage:   2,
+		Runtime: 70,
+		Help:    3,
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+	}{
+		{name: "ok", args: []string{"run"}, expCode: Success},
+		{name: "parse error", args: []string{"run", "--bogus"}, expCode: 2},
+		{name: "usability", args: []string{"run", "--usability"}, expCode: 2},
+		{name: "usage error", args: []string{"run", "--usage"}, expCode: 2},
+		{name: "no command", args: nil, expCode: 2},
+		{name: "runtime error", args: []string{"run", "--fail"}, expCode: 70},
+		{name: "panic", args: []string{"run", "-n", "a", "-n", "b"}, expCode: 70},
+		{name: "help", args: []string{"--help"}, expCode: 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    new(strings.Builder),
+				ExitCodes: codes,
+				Top: &Component{
+					Components: Components{
+						{
+							Name: "run",
+							Flags: Flags{
+								{Type: BooleanFlag, Long: "fail"},
+								{Type: BooleanFlag, Long: "usability"},
+								{Type: BooleanFlag, Long: "usage"},
+								{Type: StringFlag, Short: "n"},
+							},
+							FunctionE: func(c *Component) error {
+								_ = c.GetString("n")
+								if c.GetBool("usability") {
+									return &exitError{code: Usability}
+								}
+								if c.GetBool("usage") {
+									return UsageError(errors.New("no target"))
+								}
+								if c.GetBool("fail") {
+									return errors.New("it failed")
+								}
+								return nil
+							},
+						},
+					},
+				},
+			}
+			r := New(config)
+			must.Eq(t, tc.expCode, r.Run())
+		})
+	}
+}
+
+func TestRunnable_Parse_occurrences(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		args   []string
+		exp    error
+		expMsg string
+	}{
+		{
+			name: "within limits",
+			args: []string{"--file", "a", "--file", "b"},
+		},
+		{
+			name:   "too few",
+			args:   nil,
+			exp:    ErrMissingValue,
+			expMsg: `babycli: flag "file" must be given at least once`,
+		},
+		{
+			name:   "too many",
+			args:   []string{"--file", "a", "--file", "b", "--file", "c"},
+			exp:    ErrRepeatedFlag,
+			expMsg: `babycli: flag "file" may be given at most 2 times`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Flags: Flags{
+						{Type: StringFlag, Long: "file", Repeats: true, MinOccurrences: 1, MaxOccurrences: 2},
+					},
+					Function: func(*Component) Code { return Success },
+				},
+			}
+
+			_, err := New(config).Parse()
+			if tc.exp == nil {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, tc.exp)
+			must.EqError(t, err, tc.expMsg)
+		})
+	}
+}
+
+func TestRunnable_Parse_occurrences_separator(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expErr   string
+		expCount int
+		expPorts []int
+	}{
+		{
+			name:     "once with many values",
+			args:     []string{"--port", "80,443,8080"},
+			expCount: 1,
+			expPorts: []int{80, 443, 8080},
+		},
+		{
+			name:     "twice",
+			args:     []string{"--port", "80,443", "--port", "8080"},
+			expCount: 2,
+			expPorts: []int{80, 443, 8080},
+		},
+		{
+			name:   "too many",
+			args:   []string{"--port", "80", "--port", "443", "--port", "8080"},
+			expErr: `babycli: flag "port" may be given at most 2 times`,
+		},
+		{
+			name:   "too few",
+			args:   nil,
+			expErr: `babycli: flag "port" must be given at least once`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Flags: Flags{
+						{Type: IntFlag, Long: "port", Repeats: true, Separator: ",", MinOccurrences: 1, MaxOccurrences: 2},
+					},
+					Function: func(*Component) Code { return Success },
+				},
+			}
+
+			c, err := New(config).Parse()
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expCount, c.Count("port"))
+			must.Eq(t, tc.expPorts, c.GetInts("port"))
+		})
+	}
+}
+
+func TestRunnable_Parse_required(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Arguments: []string{"--file", "a", "--file", "b", "--file", "c"},
+		Top: &Component{
+			Flags: Flags{
+				{Type: StringFlag, Long: "name", Require: true},
+				{Type: IntFlag, Long: "count", Require: true},
+				{Type: IntFlag, Long: "size", Require: true, Default: &Default{Value: 1}},
+				{Type: StringFlag, Long: "file", Repeats: true, MaxOccurrences: 2},
+			},
+			Function: func(*Component) Code { return Success },
+		},
+	}
+
+	_, err := New(config).Parse()
+	must.ErrorIs(t, err, ErrMissingValue)
+	must.ErrorIs(t, err, ErrRepeatedFlag)
+	must.EqError(t, err, `babycli: no value for string flag "name"; no value for integer flag "count"; flag "file" may be given at most 2 times`)
+}
+
+func TestRun_usageError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("no target given")
+	stdout := new(strings.Builder)
+	stderr := new(strings.Builder)
+	code := New(&Configuration{
+		Arguments: []string{"push"},
+		Stdout:    stdout,
+		Stderr:    stderr,
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "push",
+					Help: "push a target",
+					FunctionE: func(*Component) error {
+						return fmt.Errorf("unable to push: %w", UsageError(cause))
+					},
+				},
+			},
+		},
+	}).Run()
+	must.Eq(t, Failure, code)
+	must.Eq(t, "", stdout.String())
+	must.StrHasPrefix(t, "NAME:\n  push - push a target\n", stderr.String())
+	must.StrHasSuffix(t, "\nunable to push: no target given\n", stderr.String())
+
+	must.Nil(t, UsageError(nil))
+	must.ErrorIs(t, UsageError(cause), cause)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Example is a command line showing how to use a component, listed in its
+// help under EXAMPLES.
+type Example struct {
+	// Help says what the example does.
+	Help string
+
+	// Command is the command line as typed in a shell, starting with the
+	// name of the program, like "tool deploy --wait 5m".
+	Command string
+}
+
+// ExampleError is returned by VerifyExamples for an example whose command
+// line is not valid. It wraps the error of parsing the command line, such as a
+// *ParseError for a flag or subcommand which is not defined.
+type ExampleError struct {
+	Path    []string
+	Command string
+	Err     error
+}
+
+func (e *ExampleError) Error() string {
+	reason := strings.TrimPrefix(e.Err.Error(), "babycli: ")
+	return fmt.Sprintf("babycli: example %q of %q: %s", e.Command, strings.Join(e.Path, " "), reason)
+}
+
+func (e *ExampleError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyExamples parses the command line of every Example in the component
+// tree of c, like Parse, without running any command or checking that the
+// files and directories named by flags exist. It returns an
+// *ExampleError for each one which does not parse, such as after a flag or
+// subcommand it uses was renamed or removed.
+func (c *Configuration) VerifyExamples() error {
+	var errs []error
+	c.Top.Walk(func(path []string, cmd *Component) bool {
+		for _, example := range cmd.Examples {
+			if err := c.verify(example); err != nil {
+				errs = append(errs, &ExampleError{Path: slices.Clone(path), Command: example.Command, Err: err})
+			}
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+func (c *Configuration) verify(example Example) error {
+	words, err := fields(example.Command)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return errors.New("babycli: command is empty")
+	}
+
+	config := *c
+	config.Arguments = words[1:]
+	config.ArgumentsEnv = ""
+	_, err = parseOnly(&config, true)
+	return err
+}
+
+// examples writes the EXAMPLES section of the help of c.
+func (c *Component) examples(sb *strings.Builder) {
+	if len(c.Examples) == 0 {
+		return
+	}
+	c.heading(sb, "EXAMPLES")
+	for _, example := range c.Examples {
+		if example.Help != "" {
+			sb.WriteString(tab)
+			sb.WriteString(c.style.text(example.Help))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(tab)
+		sb.WriteString(tab)
+		sb.WriteString(example.Command)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
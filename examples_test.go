@@ -0,0 +1,110 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func examplesConfig(examples ...Example) *Configuration {
+	return &Configuration{
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name:     "deploy",
+					Examples: examples,
+					Flags: Flags{
+						{Type: DurationFlag, Long: "wait"},
+						{Type: StringFlag, Long: "region", Require: true},
+						{Type: OpenFileFlag, Long: "config"},
+						{Type: DirFlag, Long: "out"},
+						{Type: GlobFlag, Long: "include", Strict: true},
+					},
+					Function: func(*Component) Code { return Success },
+				},
+			},
+		},
+	}
+}
+
+func TestConfiguration_VerifyExamples(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		command string
+		err     error
+		msg     string
+	}{
+		{name: "valid", command: "tool deploy --region 'us east' --wait 5m"},
+		{
+			name:    "nonexistent paths",
+			command: "tool deploy --region us --config /does/not/exist.hcl --out /does/not/exist --include '/does/not/*.yaml'",
+		},
+		{
+			name:    "unknown flag",
+			command: "tool deploy --region us --timeout 5m",
+			err:     ErrUnknownFlag,
+			msg:     `babycli: example "tool deploy --region us --timeout 5m" of "tool deploy": flag "timeout" is not defined`,
+		},
+		{
+			name:    "unknown command",
+			command: "tool ship --region us",
+			err:     ErrUnknownCommand,
+		},
+		{
+			name:    "bad value",
+			command: "tool deploy --region us --wait soon",
+			err:     ErrBadValue,
+		},
+		{
+			name:    "unterminated quote",
+			command: "tool deploy --region 'us",
+			msg:     `babycli: example "tool deploy --region 'us" of "tool deploy": unterminated quote`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := examplesConfig(Example{Command: tc.command}).VerifyExamples()
+			if tc.err == nil && tc.msg == "" {
+				must.NoError(t, err)
+				return
+			}
+
+			var example *ExampleError
+			must.True(t, errors.As(err, &example))
+			must.Eq(t, []string{"tool", "deploy"}, example.Path)
+			must.Eq(t, tc.command, example.Command)
+			if tc.err != nil {
+				must.ErrorIs(t, err, tc.err)
+			}
+			if tc.msg != "" {
+				must.EqError(t, err, tc.msg)
+			}
+		})
+	}
+}
+
+func TestHelp_examples(t *testing.T) {
+	t.Parallel()
+
+	w := new(strings.Builder)
+	config := examplesConfig(
+		Example{Help: "deploy to one region", Command: "tool deploy --region us"},
+		Example{Command: "tool deploy --region eu --wait 5m"},
+	)
+	config.Arguments = []string{"deploy", "--help"}
+	config.Output = w
+	must.Eq(t, Success, New(config).Run())
+
+	must.StrContains(t, w.String(), "EXAMPLES:\n  deploy to one region\n    tool deploy --region us\n    tool deploy --region eu --wait 5m\n\n")
+}
@@ -4,10 +4,17 @@
 package babycli
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"math/big"
+	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type FlagType uint8
@@ -17,6 +24,13 @@ const (
 	IntFlag
 	BooleanFlag
 	DurationFlag
+	SecretFlag
+	DateFlag
+	BigIntFlag
+	BytesLiteralFlag
+	OpenFileFlag
+	DirFlag
+	GlobFlag
 )
 
 func (t FlagType) String() string {
@@ -29,10 +43,86 @@ func (t FlagType) String() string {
 		return "boolean"
 	case DurationFlag:
 		return "duration"
+	case SecretFlag:
+		return "secret"
+	case DateFlag:
+		return "date"
+	case BigIntFlag:
+		return "big integer"
+	case BytesLiteralFlag:
+		return "bytes"
+	case OpenFileFlag:
+		return "file"
+	case DirFlag:
+		return "directory"
+	case GlobFlag:
+		return "pattern"
 	}
 	panic("babycli: not a flag type")
 }
 
+// holds returns whether v has the Go type of the values of flags of type t.
+func (t FlagType) holds(v any) bool {
+	switch v.(type) {
+	case string:
+		return slices.Contains([]FlagType{StringFlag, SecretFlag, OpenFileFlag, DirFlag, GlobFlag}, t)
+	case int:
+		return t == IntFlag
+	case bool:
+		return t == BooleanFlag
+	case time.Duration:
+		return t == DurationFlag
+	case time.Time:
+		return t == DateFlag
+	case *big.Int:
+		return t == BigIntFlag
+	case []byte:
+		return t == BytesLiteralFlag
+	}
+	return false
+}
+
+// Duplicates is how a flag without Repeats is handled when given more than once.
+type Duplicates uint8
+
+const (
+	// DuplicatesDeferred keeps every value, and reading the flag panics.
+	DuplicatesDeferred Duplicates = iota
+
+	// DuplicatesLastWins keeps only the last value given, so a value set by a
+	// shell alias can be overridden.
+	DuplicatesLastWins
+
+	// DuplicatesReject fails parsing with ErrRepeatedFlag.
+	DuplicatesReject
+)
+
+// Encoding is how the values of a BytesLiteralFlag are written.
+type Encoding uint8
+
+const (
+	// Base64 is the standard base64 alphabet, padded or not.
+	Base64 Encoding = iota
+
+	// Base64URL is the URL and filename safe base64 alphabet, padded or not.
+	Base64URL
+
+	// Hex is hexadecimal, with or without a "0x" prefix.
+	Hex
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case Base64:
+		return "base64"
+	case Base64URL:
+		return "base64url"
+	case Hex:
+		return "hex"
+	}
+	panic("babycli: not an encoding")
+}
+
 type Flag struct {
 	Type    FlagType
 	Require bool
@@ -41,18 +131,162 @@ type Flag struct {
 	Short   string
 	Help    string
 	Default *Default
+
+	// Choices restricts the accepted values of the flag.
+	Choices []string
+
+	// Placeholder names the value of the flag in help, in place of its type.
+	Placeholder string
+
+	// MinOccurrences and MaxOccurrences limit how many times the flag may be
+	// given on the command line. A zero value is no limit.
+	MinOccurrences int
+	MaxOccurrences int
+
+	// Hidden flags can be given but are left out of help and documentation.
+	Hidden bool
+
+	// Deprecated flags can be given with a warning of this message, such as
+	// naming their replacement, but are left out of help and documentation.
+	Deprecated string
+
+	// Group lists the flag in help under a heading of its own, named like
+	// "<GROUP> OPTIONS", instead of under OPTIONS.
+	Group string
+
+	// Verbosity makes a repeating boolean flag lower the Level of the
+	// command each time it is given, from Warn to Info to Debug, like the
+	// --verbose flag of Configuration.Verbosity. Its short name may be
+	// repeated, like "-vv".
+	Verbosity bool
+
+	// Transform rewrites each value given for the flag before it is checked
+	// and converted, such as to trim or lowercase it. An error fails parsing
+	// with ErrBadValue.
+	Transform func(value string) (string, error)
+
+	// Separator splits each value given for a repeating string, integer, or
+	// duration flag into several values, such as "," for "--port 80,443".
+	Separator string
+
+	// Layout is the time layout of the values of a DateFlag (default
+	// time.DateOnly, like "2024-06-01").
+	Layout string
+
+	// Encoding is how the values of a BytesLiteralFlag are written (default
+	// Base64). Values in any other encoding fail parsing.
+	Encoding Encoding
+
+	// CreateIfMissing creates the directories given for a DirFlag which do
+	// not exist, with their parents, before the command runs.
+	CreateIfMissing bool
+
+	// Perm is the permission of the directories created for a DirFlag
+	// (default 0o755), before the umask.
+	Perm os.FileMode
+
+	// Match expands the patterns given for a GlobFlag into paths (default
+	// filepath.Glob), and may be replaced such as to support "**".
+	Match func(pattern string) ([]string, error)
+
+	// Strict fails parsing when a pattern given for a GlobFlag matches
+	// nothing.
+	Strict bool
+
+	// ExpandEnv expands a leading "~" to the home directory, and $VAR or
+	// ${VAR} to the value of the environment variable, in each value given
+	// for the flag, before any Transform.
+	ExpandEnv bool
+
+	// Annotations carry metadata for tools built around the flag, through
+	// Spec, and are listed in the Markdown and man pages. Completion offers
+	// file names for the value of a flag annotated with "file-completion" set
+	// to "true".
+	Annotations map[string]string
 }
 
 type Default struct {
 	Value any
 	Show  bool
+
+	// Func computes the default when the flag is not given, in place of Value.
+	// A Spec records only that the default is computed, leaving Func to be
+	// set again after Load.
+	Func func() any
+
+	// Hidden keeps the default out of help and documentation, for sensitive
+	// values, even if Show is set. A Spec records only that the default is
+	// hidden, leaving its Value to be set again after Load.
+	Hidden bool
+}
+
+func (d *Default) value() any {
+	if d.Func != nil {
+		return d.Func()
+	}
+	return d.Value
+}
+
+func (f *Flag) layout() string {
+	if f.Layout == "" {
+		return time.DateOnly
+	}
+	return f.Layout
+}
+
+// decode decodes value in the Encoding of f only, so it is never decoded by
+// guessing. Padded and unpadded base64 decode alike.
+func (f *Flag) decode(value string) ([]byte, bool) {
+	var encodings []*base64.Encoding
+	switch f.Encoding {
+	case Hex:
+		b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		return b, err == nil
+	case Base64URL:
+		encodings = []*base64.Encoding{base64.URLEncoding, base64.RawURLEncoding}
+	default:
+		encodings = []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding}
+	}
+	for _, encoding := range encodings {
+		if b, err := encoding.DecodeString(value); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (f *Flag) perm() os.FileMode {
+	if f.Perm == 0 {
+		return 0o755
+	}
+	return f.Perm
+}
+
+// glob returns the paths matching pattern, with the Match of f.
+func (f *Flag) glob(pattern string) ([]string, error) {
+	if f.Match == nil {
+		return filepath.Glob(pattern)
+	}
+	return f.Match(pattern)
+}
+
+// transform returns value rewritten by the Transform of f, if any.
+func (f *Flag) transform(value string) (string, error) {
+	if f.Transform == nil {
+		return value, nil
+	}
+	transformed, err := f.Transform(value)
+	if err != nil {
+		return "", parsef(ErrBadValue, "unable to transform value for flag %q: %v", f.Identity(), err)
+	}
+	return transformed, nil
 }
 
 func (f *Flag) showDefault() bool {
-	return f.Default != nil && f.Default.Show
+	return f.Default != nil && f.Default.Show && !f.Default.Hidden && f.Type != SecretFlag
 }
 
-func (f *Flag) help() [3]string {
+func (f *Flag) help(s *style) [3]string {
 	var parts [3]string
 	switch {
 	case f.Long != "" && f.Short != "":
@@ -63,16 +297,84 @@ func (f *Flag) help() [3]string {
 		parts[0] = "-" + f.Short
 	}
 
-	parts[1] = f.Type.String()
-	parts[2] = f.Help
+	parts[1] = f.metavar()
+	parts[2] = s.text(f.Help)
+
+	if len(f.Choices) > 0 {
+		parts[2] = fmt.Sprintf("%s %s", parts[2], f.choices())
+	}
+
+	if occurrences := f.occurrences(); occurrences != "" {
+		parts[2] = fmt.Sprintf("%s (%s)", parts[2], occurrences)
+	}
 
 	if f.showDefault() {
-		parts[2] = fmt.Sprintf("%s (%v)", parts[2], f.Default.Value)
+		parts[2] = fmt.Sprintf("%s (%v)", parts[2], f.Default.value())
 	}
 
 	return parts
 }
 
+// occurrences describes the limits on the number of times f may be given.
+func (f *Flag) occurrences() string {
+	switch {
+	case f.MinOccurrences > 0 && f.MaxOccurrences > 0:
+		return fmt.Sprintf("%d to %d times", f.MinOccurrences, f.MaxOccurrences)
+	case f.MinOccurrences > 0:
+		return "at least " + times(f.MinOccurrences)
+	case f.MaxOccurrences > 0:
+		return "at most " + times(f.MaxOccurrences)
+	default:
+		return ""
+	}
+}
+
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+	return strconv.Itoa(n) + " times"
+}
+
+// check returns an error if f is required but was not given, or if the
+// number of values given for f is outside of its limits.
+func (f *Flag) check(n int) *ParseError {
+	switch {
+	case f.Require && n == 0 && f.Default == nil && f.Type != SecretFlag:
+		return parsef(ErrMissingValue, "no value for %s flag %q", f.Type, f.Identity())
+	case f.MinOccurrences > 0 && n < f.MinOccurrences:
+		return parsef(ErrMissingValue, "flag %q must be given at least %s", f.Identity(), times(f.MinOccurrences))
+	case f.MaxOccurrences > 0 && n > f.MaxOccurrences:
+		return parsef(ErrRepeatedFlag, "flag %q may be given at most %s", f.Identity(), times(f.MaxOccurrences))
+	default:
+		return nil
+	}
+}
+
+func (f *Flag) choices() string {
+	return "[" + strings.Join(f.Choices, "|") + "]"
+}
+
+// synopsis is how the flag appears in a usage line.
+func (f *Flag) synopsis() string {
+	name := "--" + f.Long
+	if f.Long == "" {
+		name = "-" + f.Short
+	}
+	if f.Type == BooleanFlag {
+		return name
+	}
+	return fmt.Sprintf("%s <%s>", name, f.metavar())
+}
+
+// metavar is the name of the value of the flag in help.
+func (f *Flag) metavar() string {
+	if f.Placeholder != "" {
+		return f.Placeholder
+	}
+	return f.Type.String()
+}
+
 func (f *Flag) Identity() string {
 	if f.Long == "" {
 		return f.Short
@@ -95,6 +397,26 @@ func (fs Flags) Contains(name string) bool {
 	})
 }
 
+func (fs Flags) visible() Flags {
+	return slices.DeleteFunc(slices.Clone(fs), func(f *Flag) bool {
+		return f.Hidden || f.Deprecated != ""
+	})
+}
+
+// index maps the long and short names of fs to their flags, keeping the
+// first flag of each name, as found by Get.
+func (fs Flags) index() map[string]*Flag {
+	index := make(map[string]*Flag, 2*len(fs))
+	for _, f := range fs {
+		for _, name := range []string{f.Long, f.Short} {
+			if _, exists := index[name]; name != "" && !exists {
+				index[name] = f
+			}
+		}
+	}
+	return index
+}
+
 func (fs Flags) Get(name string) *Flag {
 	for _, f := range fs {
 		if f.Is(name) {
@@ -105,25 +427,29 @@ func (fs Flags) Get(name string) *Flag {
 	return nil
 }
 
-func (fs Flags) write(w io.Writer) {
+func (fs Flags) write(w io.Writer, s *style) {
 	lines := make([][3]string, 0, len(fs))
 	for _, flag := range fs {
-		lines = append(lines, flag.help())
+		lines = append(lines, flag.help(s))
 	}
 
-	var max0, max1 int
+	var max1 int
+	names := make([]string, 0, len(lines))
 
 	for i := 0; i < len(lines); i++ {
-		max0 = max(max0, len(lines[i][0]))
+		names = append(names, lines[i][0])
 		max1 = max(max1, len(lines[i][1]))
 	}
 
+	max0 := s.nameWidth(names)
+	indent := max0 + s.helpLayout().Gap + max1 + 6
+
 	for _, line := range lines {
-		_, _ = io.WriteString(w, rightPad(max0, line[0]))
+		s.writeName(w, line[0], max0)
 		_, _ = io.WriteString(w, " ")
 		_, _ = io.WriteString(w, leftPad(max1, line[1]))
 		_, _ = io.WriteString(w, "- ")
-		_, _ = io.WriteString(w, line[2])
+		s.writeHelp(w, line[2], indent)
 		_, _ = io.WriteString(w, "\n")
 	}
 }
@@ -0,0 +1,175 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/base64"
+	"flag"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// FromFlagSet converts the flags defined in fs, so a command written with the
+// flag package can be mounted as a Component. Flags of types other than bool,
+// int, and time.Duration become string flags. Use Apply to set the variables
+// of fs from the values parsed by babycli.
+func FromFlagSet(fs *flag.FlagSet) Flags {
+	var flags Flags
+	fs.VisitAll(func(f *flag.Flag) {
+		converted := &Flag{
+			Type: StringFlag,
+			Help: f.Usage,
+		}
+
+		if len(f.Name) == 1 {
+			converted.Short = f.Name
+		} else {
+			converted.Long = f.Name
+		}
+
+		var value any = f.DefValue
+		if getter, ok := f.Value.(flag.Getter); ok {
+			switch v := getter.Get().(type) {
+			case bool:
+				converted.Type, value = BooleanFlag, v
+			case int:
+				converted.Type, value = IntFlag, v
+			case time.Duration:
+				converted.Type, value = DurationFlag, v
+			}
+		}
+		converted.Default = &Default{Value: value, Show: !zero(f.DefValue)}
+
+		flags = append(flags, converted)
+	})
+	return flags
+}
+
+func zero(s string) bool {
+	switch s {
+	case "", "0", "false", "0s":
+		return true
+	}
+	return false
+}
+
+// ToFlagSet defines flags in a new flag.FlagSet named name, so a Component
+// can be handed to code expecting the flag package. A flag with both a long
+// and a short name is defined under both. The flag package keeps only the
+// last value of a flag given more than once.
+func ToFlagSet(name string, flags Flags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	for _, f := range flags {
+		for _, n := range []string{f.Long, f.Short} {
+			if n == "" {
+				continue
+			}
+			switch f.Type {
+			case StringFlag, SecretFlag, OpenFileFlag, DirFlag, GlobFlag:
+				fs.String(n, flagSetDefault(f, ""), f.Help)
+			case IntFlag:
+				fs.Int(n, flagSetDefault(f, 0), f.Help)
+			case BooleanFlag:
+				fs.Bool(n, flagSetDefault(f, false), f.Help)
+			case DurationFlag:
+				fs.Duration(n, flagSetDefault(f, time.Duration(0)), f.Help)
+			case DateFlag:
+				var value string
+				if d := flagSetDefault(f, time.Time{}); !d.IsZero() {
+					value = d.Format(f.layout())
+				}
+				fs.String(n, value, f.Help)
+			case BigIntFlag:
+				var value string
+				if f.Default != nil {
+					value = f.Default.value().(*big.Int).String()
+				}
+				fs.String(n, value, f.Help)
+			case BytesLiteralFlag:
+				fs.String(n, base64.StdEncoding.EncodeToString(flagSetDefault(f, []byte(nil))), f.Help)
+			}
+		}
+	}
+	return fs
+}
+
+func flagSetDefault[T any](f *Flag, fallback T) T {
+	if f.Default == nil {
+		return fallback
+	}
+	if value, ok := f.Default.value().(T); ok {
+		return value
+	}
+	return fallback
+}
+
+// Apply sets each flag of fs defined on c to the values given to c on the
+// command line, in order, leaving the defaults of fs for flags not given.
+func (c *Component) Apply(fs *flag.FlagSet) error {
+	flags := c.combine()
+
+	var err error
+	fs.VisitAll(func(ff *flag.Flag) {
+		if err != nil || !flags.Contains(ff.Name) {
+			return
+		}
+
+		f := flags.Get(ff.Name)
+		for _, value := range c.vals.format(f) {
+			if err = fs.Set(ff.Name, value); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+// format returns the values given for f as strings.
+func (v *values) format(f *Flag) []string {
+	identity := f.Identity()
+
+	var formatted []string
+	switch f.Type {
+	case StringFlag:
+		formatted = append(formatted, v.strings[identity]...)
+	case SecretFlag:
+		for _, s := range v.secrets[identity] {
+			formatted = append(formatted, string(s))
+		}
+	case IntFlag:
+		for _, i := range v.ints[identity] {
+			formatted = append(formatted, strconv.Itoa(i))
+		}
+	case BooleanFlag:
+		for _, b := range v.bools[identity] {
+			formatted = append(formatted, strconv.FormatBool(b))
+		}
+	case DurationFlag:
+		for _, d := range v.durations[identity] {
+			formatted = append(formatted, d.String())
+		}
+	case DateFlag:
+		for _, t := range v.dates[identity] {
+			formatted = append(formatted, t.Format(f.layout()))
+		}
+	case BigIntFlag:
+		for _, i := range v.bigs[identity] {
+			formatted = append(formatted, i.String())
+		}
+	case BytesLiteralFlag:
+		for _, b := range v.bytes[identity] {
+			formatted = append(formatted, base64.StdEncoding.EncodeToString(b))
+		}
+	case OpenFileFlag:
+		formatted = append(formatted, v.files[identity]...)
+	case DirFlag:
+		formatted = append(formatted, v.dirs[identity]...)
+	case GlobFlag:
+		for _, ps := range v.globs[identity] {
+			formatted = append(formatted, ps...)
+		}
+	}
+	return formatted
+}
@@ -0,0 +1,74 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestFromFlagSet(t *testing.T) {
+	t.Parallel()
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	workers := fs.Int("workers", 0, "number of workers")
+	debug := fs.Bool("d", false, "enable debugging")
+	grace := fs.Duration("grace", 5*time.Second, "shutdown grace period")
+	ratio := fs.Float64("ratio", 0.5, "sample ratio")
+
+	flags := FromFlagSet(fs)
+	must.Len(t, 5, flags)
+	must.Eq(t, BooleanFlag, flags.Get("d").Type)
+	must.Eq(t, DurationFlag, flags.Get("grace").Type)
+	must.Eq(t, StringFlag, flags.Get("ratio").Type)
+	must.Eq(t, IntFlag, flags.Get("workers").Type)
+	must.True(t, flags.Get("addr").Default.Show)
+	must.False(t, flags.Get("workers").Default.Show)
+
+	config := &Configuration{
+		Arguments: []string{"serve", "--workers", "4", "-d", "--ratio", "0.25"},
+		Output:    new(strings.Builder),
+		Top: &Component{
+			Components: Components{
+				{
+					Name:  "serve",
+					Flags: flags,
+					FunctionE: func(c *Component) error {
+						return c.Apply(fs)
+					},
+				},
+			},
+		},
+	}
+
+	must.Zero(t, New(config).Run())
+	must.Eq(t, ":8080", *addr)
+	must.Eq(t, 4, *workers)
+	must.True(t, *debug)
+	must.Eq(t, 5*time.Second, *grace)
+	must.Eq(t, 0.25, *ratio)
+}
+
+func TestToFlagSet(t *testing.T) {
+	t.Parallel()
+
+	fs := ToFlagSet("serve", Flags{
+		{Type: StringFlag, Long: "addr", Short: "a", Default: &Default{Value: ":8080"}},
+		{Type: IntFlag, Long: "workers"},
+		{Type: DurationFlag, Long: "grace", Default: &Default{Value: time.Second}},
+		{Type: DurationFlag, Long: "timeout", Default: &Default{Value: 30}},
+	})
+
+	must.NoError(t, fs.Parse([]string{"-a", ":9090", "--workers", "2"}))
+	must.Eq(t, ":9090", fs.Lookup("a").Value.String())
+	must.Eq(t, ":8080", fs.Lookup("addr").Value.String())
+	must.Eq(t, "2", fs.Lookup("workers").Value.String())
+	must.Eq(t, "1s", fs.Lookup("grace").Value.String())
+	must.Eq(t, "0s", fs.Lookup("timeout").Value.String())
+}
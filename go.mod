@@ -2,9 +2,6 @@ module noxide.lol/go/babycli
 
 go 1.23
 
-require (
-	github.com/shoenig/test v1.8.2
-	noxide.lol/go/stacks v1.0.0
-)
+require github.com/shoenig/test v1.8.2
 
 require github.com/google/go-cmp v0.6.0 // indirect
@@ -4,7 +4,9 @@
 package babycli
 
 import (
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 )
 
@@ -17,90 +19,346 @@ var helpFlag = &Flag{
 	Help:    "print help message",
 }
 
+// helpAllFlag prints the tree of commands, and is also given as "help --all".
+var helpAllFlag = &Flag{
+	Type:   BooleanFlag,
+	Long:   "help-all",
+	Help:   "print every command below this one",
+	Hidden: true,
+}
+
+var helpComponent = &Component{
+	Name: "help",
+	Help: "print help for a command",
+}
+
 const (
 	tab = "  "
 )
 
-func (c Components) write(w io.Writer) {
+func (c Components) write(w io.Writer, s *style) {
 	lines := make([][2]string, 0, len(c))
 
 	for _, component := range c {
-		lines = append(lines, [2]string{component.Name, component.Help})
+		lines = append(lines, [2]string{component.Name, s.text(component.Help)})
 	}
 
-	var max0 int
+	names := make([]string, 0, len(lines))
 
 	for i := 0; i < len(lines); i++ {
-		max0 = max(max0, len(lines[i][0]))
+		names = append(names, lines[i][0])
 	}
 
+	max0 := s.nameWidth(names)
+	indent := max0 + s.helpLayout().Gap + 5
+
 	for _, line := range lines {
 		_, _ = io.WriteString(w, "  ")
-		_, _ = io.WriteString(w, rightPad(max0, line[0]))
+		if len(line[0]) > max0 {
+			_, _ = io.WriteString(w, line[0])
+			_, _ = io.WriteString(w, "\n  ")
+			line[0] = ""
+		}
+		s.writeName(w, line[0], max0)
 		_, _ = io.WriteString(w, "- ")
-		_, _ = io.WriteString(w, line[1])
+		s.writeHelp(w, line[1], indent)
 		_, _ = io.WriteString(w, "\n")
 	}
 }
 
+type category struct {
+	name       string
+	components Components
+}
+
+// categories groups components by their Category, with uncategorized
+// components first and the rest in order of first appearance.
+func (c Components) categories() []category {
+	groups := make([]category, 0, 1)
+	index := make(map[string]int)
+
+	for _, component := range c {
+		if _, exists := index[component.Category]; !exists {
+			index[component.Category] = len(groups)
+			groups = append(groups, category{name: component.Category})
+		}
+		i := index[component.Category]
+		groups[i].components = append(groups[i].components, component)
+	}
+
+	slices.SortStableFunc(groups, func(a, b category) int {
+		switch {
+		case a.name == "" && b.name != "":
+			return -1
+		case a.name != "" && b.name == "":
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return groups
+}
+
+type group struct {
+	name  string
+	flags Flags
+}
+
+// groups groups flags by their Group, with ungrouped flags first and the rest
+// in order of first appearance.
+func (fs Flags) groups() []group {
+	groups := make([]group, 0, 1)
+	index := make(map[string]int)
+
+	for _, f := range fs {
+		if _, exists := index[f.Group]; !exists {
+			index[f.Group] = len(groups)
+			groups = append(groups, group{name: f.Group})
+		}
+		i := index[f.Group]
+		groups[i].flags = append(groups[i].flags, f)
+	}
+
+	slices.SortStableFunc(groups, func(a, b group) int {
+		switch {
+		case a.name == "" && b.name != "":
+			return -1
+		case a.name != "" && b.name == "":
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return groups
+}
+
+// commands returns the subcommands listed in help, including the built-in
+// help command unless it has been replaced.
+func (c *Component) commands() Components {
+	visible := c.allowedComponents()
+	if c.Leaf() || c.Components.Contains(helpComponent.Name) {
+		return visible
+	}
+	return append(visible, helpComponent)
+}
+
+// helpTopic resolves the remaining arguments as a path of subcommands below
+// c, and marks the component found to print its help.
+func (c *Component) helpTopic() (*Component, error) {
+	target := c
+	flag := helpFlag
+	for !c.args.empty() {
+		name := c.args.next()
+		if name == "--all" {
+			flag = helpAllFlag
+			continue
+		}
+		next := target.child(name)
+		if next == nil {
+			return nil, target.attach(parsef(ErrUnknownCommand, "subcommand %q is not defined", name))
+		}
+		if err := next.available(); err != nil {
+			return nil, target.attach(err)
+		}
+		target = next
+	}
+	record(&target.vals.bools, flag.Long, true)
+	return target, nil
+}
+
+// heading writes the translated title of a help section.
+func (c *Component) heading(sb *strings.Builder, title string) {
+	sb.WriteString(c.style.text(title))
+	sb.WriteString(":\n")
+}
+
 func (c *Component) help() string {
 	sb := new(strings.Builder)
-	sb.WriteString("NAME:\n")
+	c.heading(sb, "NAME")
 	sb.WriteString(tab)
 	sb.WriteString(c.Name)
 	if c.Help != "" {
 		sb.WriteString(" - ")
-		sb.WriteString(c.Help)
+		sb.WriteString(c.style.text(c.Help))
 	}
 	sb.WriteString("\n\n")
 
-	sb.WriteString("USAGE:\n")
+	c.heading(sb, "USAGE")
 	sb.WriteString(tab)
-	sb.WriteString(c.Name)
-	sb.WriteString(tab)
-	sb.WriteString("[global options] [command [command options]] [arguments...]")
+	writeWrapped(sb, c.usage(), len(tab)+len(tab), c.style.cols())
 	sb.WriteString("\n\n")
 
-	if c.version != "" {
-		sb.WriteString("VERSION:\n")
+	if c.parent == nil && c.version != nil {
+		c.heading(sb, "VERSION")
 		sb.WriteString(tab)
-		sb.WriteString(c.version)
+		sb.WriteString(c.version.String())
 		sb.WriteString("\n\n")
 	}
 
 	if c.Description != "" {
-		sb.WriteString("DESCRIPTION:\n")
-		lines := chop(c.Description)
+		c.heading(sb, "DESCRIPTION")
+		lines := chop(c.style.text(c.Description))
 		for _, line := range lines {
-			sb.WriteString(tab)
-			sb.WriteString(line)
-			sb.WriteString("\n")
+			for _, wrapped := range wrap(line, c.style.cols()-len(tab)) {
+				sb.WriteString(tab)
+				sb.WriteString(wrapped)
+				sb.WriteString("\n")
+			}
 		}
 		sb.WriteString("\n")
 	}
 
-	if len(c.Components) > 0 {
-		sb.WriteString("COMMANDS:\n")
-		c.Components.write(sb)
+	c.examples(sb)
+
+	for _, group := range c.commands().categories() {
+		if group.name == "" {
+			c.heading(sb, "COMMANDS")
+		} else {
+			c.heading(sb, strings.ToUpper(group.name)+" "+c.style.text("COMMANDS"))
+		}
+		c.style.components(group.components).write(sb, c.style)
 		sb.WriteString("\n")
 	}
 
-	if len(c.Flags) > 0 {
-		sb.WriteString("OPTIONS:\n")
-		c.Flags.write(sb)
+	for _, group := range c.options().visible().groups() {
+		if group.name == "" {
+			c.heading(sb, "OPTIONS")
+		} else {
+			c.heading(sb, strings.ToUpper(group.name)+" "+c.style.text("OPTIONS"))
+		}
+		c.style.flags(group.flags).write(sb, c.style)
 		sb.WriteString("\n")
 	}
 
-	if len(c.globals) > 0 {
-		sb.WriteString("GLOBALS:\n")
-		c.globals.write(sb)
-		sb.WriteString("\n")
+	if globals := slices.Concat(c.inherited(), c.ancestral(), c.globals).visible(); len(globals) > 0 {
+		switch {
+		case c.parent == nil || c.style.globalsHelp() == GlobalsListed:
+			c.heading(sb, "GLOBALS")
+			c.style.flags(globals).write(sb, c.style)
+			sb.WriteString("\n")
+		case c.style.globalsHelp() == GlobalsCollapsed:
+			c.heading(sb, "GLOBALS")
+			sb.WriteString(tab)
+			fmt.Fprintf(sb, c.style.text("run %q to list the global options"), program(c.lineage()[0])+" --help")
+			sb.WriteString("\n")
+		}
 	}
 
 	s := sb.String()
 	return strings.TrimSpace(s)
 }
 
+// helpAllSet returns whether --help-all was given.
+func (c *Component) helpAllSet() bool {
+	return slices.Contains(c.vals.bools[helpAllFlag.Long], true)
+}
+
+// tree returns the names and help of c and every visible command below it,
+// indented by depth.
+func (c *Component) tree() string {
+	sb := new(strings.Builder)
+	var add func(cmd *Component, name string, depth int)
+	add = func(cmd *Component, name string, depth int) {
+		sb.WriteString(strings.Repeat(tab, depth))
+		sb.WriteString(name)
+		if cmd.Help != "" {
+			sb.WriteString(" - ")
+			sb.WriteString(c.style.text(cmd.Help))
+		}
+		sb.WriteString("\n")
+		for _, sub := range c.style.components(cmd.allowedComponents()) {
+			add(cmd.descend(sub), sub.Name, depth+1)
+		}
+	}
+
+	add(c, strings.Join(c.path(), " "), 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// HelpText returns the help message of the component reached by the path of
+// subcommand names below the top component, without parsing any arguments.
+// It panics if the path does not exist.
+func (r *Runnable) HelpText(path ...string) string {
+	c := r.root
+	for _, name := range path {
+		c = c.descend(c.Components.Get(name))
+	}
+	return c.help()
+}
+
+// path returns the names of the components from the top down to c.
+func (c *Component) path() []string {
+	var names []string
+	for _, p := range c.lineage() {
+		if p.Name != "" {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// usage generates the one line synopsis of c from its specification.
+func (c *Component) usage() string {
+	parts := append(c.path(), c.arguments()...)
+	return strings.Join(parts, " ")
+}
+
+// arguments returns the parts of the usage line following the command path.
+func (c *Component) arguments() []string {
+	var parts []string
+
+	globals := slices.Concat(c.inherited(), c.ancestral())
+	if c.state != nil {
+		globals = append(globals, c.globals...)
+	}
+	if slices.ContainsFunc(globals.visible(), func(f *Flag) bool { return f != helpFlag }) {
+		parts = append(parts, "[global options]")
+	}
+
+	optional := false
+	for _, f := range c.options().visible() {
+		if f.Require && f.Default == nil {
+			parts = append(parts, f.synopsis())
+		} else {
+			optional = true
+		}
+	}
+
+	if optional {
+		parts = append(parts, "[options]")
+	}
+
+	switch {
+	case c.Leaf():
+		parts = append(parts, "[arguments...]")
+	case c.RunWithoutSubcommand && c.runnable():
+		parts = append(parts, "[command]", "[arguments...]")
+	case c.Default != "" || c.runnable():
+		parts = append(parts, "[command]")
+	default:
+		parts = append(parts, "<command>")
+	}
+
+	return parts
+}
+
+// hint is printed after a usage error, pointing at the full help message.
+func (c *Component) hint() string {
+	sb := new(strings.Builder)
+	sb.WriteString(c.style.text("USAGE"))
+	sb.WriteString(": ")
+	sb.WriteString(c.usage())
+	sb.WriteString("\n")
+	if names := c.path(); len(names) > 0 {
+		_, _ = fmt.Fprintf(sb, c.style.text("Run '%s --help' for more information."), strings.Join(names, " "))
+	} else {
+		sb.WriteString(c.style.text("Run with --help for more information."))
+	}
+	return sb.String()
+}
+
 func chop(s string) []string {
 	s = strings.TrimSpace(s)
 	return strings.Split(s, "\n")
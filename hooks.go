@@ -0,0 +1,112 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// lineage returns the chain of components from the top down to c.
+func (c *Component) lineage() []*Component {
+	var chain []*Component
+	for p := c; p != nil; p = p.parent {
+		chain = append(chain, p)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+func (c *Component) execute() *result {
+	chain := c.lineage()
+
+	if err := c.createDirs(); err != nil {
+		return &result{code: Failure, err: err, kind: runtimeKind}
+	}
+
+	// done counts the levels whose Before completed, and so whose After
+	// runs even if a Before further down fails.
+	var res *result
+	done := 0
+	for _, p := range chain {
+		if p.Before != nil {
+			start := time.Now()
+			code := p.Before(c)
+			c.measure(hookStep("before", p), start)
+			if code != Success {
+				res = &result{code: code}
+				break
+			}
+		}
+		done++
+	}
+
+	if res == nil {
+		res = c.profiled()
+	}
+
+	for i := done - 1; i >= 0; i-- {
+		if chain[i].After == nil {
+			continue
+		}
+		start := time.Now()
+		code := chain[i].After(c)
+		c.measure(hookStep("after", chain[i]), start)
+		if res.code == Success {
+			res.code = code
+		}
+	}
+
+	return res
+}
+
+// profiled calls the Function of c, with profiling started if it was asked
+// for.
+func (c *Component) profiled() *result {
+	if err := c.startProfiling(); err != nil {
+		return &result{code: Failure, err: err, kind: runtimeKind}
+	}
+
+	start := time.Now()
+	res := c.call()
+	c.measure("function", start)
+	return res
+}
+
+// hookStep names a hook of p in a profile.
+func hookStep(hook string, p *Component) string {
+	return strings.Join(append([]string{hook}, p.path()...), " ")
+}
+
+type exitCoder interface {
+	ExitCode() int
+}
+
+func (c *Component) call() *result {
+	if c.fallback {
+		return &result{code: c.Fallback(c)}
+	}
+
+	if c.Function != nil {
+		return &result{code: c.Function(c)}
+	}
+
+	err := c.FunctionE(c)
+	if err == nil {
+		return &result{code: Success}
+	}
+
+	var usage *usageError
+	if errors.As(err, &usage) {
+		return &result{code: Failure, err: err, kind: usageKind}
+	}
+
+	var coder exitCoder
+	if errors.As(err, &coder) {
+		return &result{code: coder.ExitCode(), err: err}
+	}
+	return &result{code: Failure, err: err, kind: runtimeKind}
+}
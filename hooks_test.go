@@ -0,0 +1,123 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRun_hooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	hook := func(s string, code Code) Func {
+		return func(*Component) Code {
+			calls = append(calls, s)
+			return code
+		}
+	}
+
+	config := &Configuration{
+		Arguments: []string{"db", "migrate"},
+		Top: &Component{
+			Before: hook("top before", Success),
+			After:  hook("top after", Success),
+			Components: Components{
+				{
+					Name:   "db",
+					Before: hook("db before", Success),
+					After:  hook("db after", Success),
+					Components: Components{
+						{
+							Name:     "migrate",
+							Before:   hook("migrate before", Success),
+							Function: hook("migrate", Success),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Eq(t, Success, result)
+	must.Eq(t, []string{
+		"top before",
+		"db before",
+		"migrate before",
+		"migrate",
+		"db after",
+		"top after",
+	}, calls)
+}
+
+func TestRun_hooks_before_failure(t *testing.T) {
+	t.Parallel()
+
+	var ran bool
+
+	config := &Configuration{
+		Arguments: []string{"child"},
+		Top: &Component{
+			Before: func(*Component) Code {
+				return 7
+			},
+			Components: Components{
+				{
+					Name: "child",
+					Function: func(*Component) Code {
+						ran = true
+						return Success
+					},
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Eq(t, 7, result)
+	must.False(t, ran)
+}
+
+func TestRun_hooks_before_failure_unwinds(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	hook := func(s string, code Code) Func {
+		return func(*Component) Code {
+			calls = append(calls, s)
+			return code
+		}
+	}
+
+	config := &Configuration{
+		Arguments: []string{"db", "migrate"},
+		Top: &Component{
+			Before: hook("top before", Success),
+			After:  hook("top after", Success),
+			Components: Components{
+				{
+					Name:   "db",
+					Before: hook("db before", 7),
+					After:  hook("db after", Success),
+					Components: Components{
+						{
+							Name:     "migrate",
+							Before:   hook("migrate before", Success),
+							After:    hook("migrate after", Success),
+							Function: hook("migrate", Success),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	must.Eq(t, 7, New(config).Run())
+	must.Eq(t, []string{"top before", "db before", "top after"}, calls)
+}
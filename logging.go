@@ -0,0 +1,88 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+const defaultLevel = slog.LevelWarn
+
+var (
+	quietFlag = &Flag{
+		Type:  BooleanFlag,
+		Long:  "quiet",
+		Short: "q",
+		Help:  "only log errors",
+	}
+
+	verboseFlag = &Flag{
+		Type:      BooleanFlag,
+		Repeats:   true,
+		Long:      "verbose",
+		Short:     "v",
+		Help:      "log more details (repeatable)",
+		Verbosity: true,
+	}
+)
+
+// Level returns the log level selected by the --quiet flag and the
+// Verbosity flags, such as --verbose. Each Verbosity flag given lowers the
+// level from Warn, to Info, to Debug.
+func (c *Component) Level() slog.Level {
+	level := defaultLevel
+
+	seen := make(map[string]bool)
+	for _, f := range c.combine() {
+		if !f.Verbosity || seen[f.Identity()] {
+			continue
+		}
+		seen[f.Identity()] = true
+		for _, b := range c.vals.bools[f.Identity()] {
+			if b {
+				level -= 4
+			}
+		}
+	}
+	level = max(level, slog.LevelDebug)
+
+	for _, b := range c.vals.bools[quietFlag.Long] {
+		if b {
+			level = slog.LevelError
+		}
+	}
+
+	return level
+}
+
+// Logger returns a logger writing to Stderr at the Level of the command.
+func (c *Component) Logger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(c.stderr, &slog.HandlerOptions{
+		Level: c.Level(),
+	}))
+}
+
+// verbosityCluster returns the Verbosity flag whose short name is repeated
+// to make name, like "vvv".
+func (fs Flags) verbosityCluster(name string) *Flag {
+	if len(name) < 2 || strings.Trim(name, name[:1]) != "" {
+		return nil
+	}
+	for _, f := range fs {
+		if f.Verbosity && f.Short == name[:1] {
+			return f
+		}
+	}
+	return nil
+}
+
+// discard is a slog.Handler which drops every record.
+type discard struct{}
+
+func (discard) Enabled(context.Context, slog.Level) bool  { return false }
+func (discard) Handle(context.Context, slog.Record) error { return nil }
+func (d discard) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discard) WithGroup(string) slog.Handler           { return d }
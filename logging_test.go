@@ -0,0 +1,139 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestComponent_Logger(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expLevel slog.Level
+		expLines int
+	}{
+		{name: "default", args: nil, expLevel: slog.LevelWarn, expLines: 2},
+		{name: "quiet", args: []string{"-q"}, expLevel: slog.LevelError, expLines: 1},
+		{name: "verbose", args: []string{"-v"}, expLevel: slog.LevelInfo, expLines: 3},
+		{name: "very verbose", args: []string{"-v", "--verbose"}, expLevel: slog.LevelDebug, expLines: 4},
+		{name: "extremely verbose", args: []string{"-v", "-v", "-v"}, expLevel: slog.LevelDebug, expLines: 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var level slog.Level
+			stderr := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Stderr:    stderr,
+				Verbosity: true,
+				Top: &Component{
+					Function: func(c *Component) Code {
+						level = c.Level()
+						logger := c.Logger()
+						logger.Debug("debug")
+						logger.Info("info")
+						logger.Warn("warn")
+						logger.Error("error")
+						return Success
+					},
+				},
+			}
+			result := New(config).Run()
+			must.Eq(t, Success, result)
+			must.Eq(t, tc.expLevel, level)
+			must.Eq(t, tc.expLines, strings.Count(stderr.String(), "\n"))
+		})
+	}
+}
+
+func TestFlag_Verbosity(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expLevel slog.Level
+		expOut   string
+	}{
+		{name: "default", args: nil, expLevel: slog.LevelWarn},
+		{name: "verbose", args: []string{"run", "-v"}, expLevel: slog.LevelInfo},
+		{name: "cluster", args: []string{"run", "-vv"}, expLevel: slog.LevelDebug},
+		{name: "long", args: []string{"run", "--chatty", "--chatty=false"}, expLevel: slog.LevelInfo},
+		{
+			name:   "double dash cluster",
+			args:   []string{"run", "--vv"},
+			expOut: "babycli: flag \"vv\" is not defined\nUSAGE: tool run [global options] [arguments...]\nRun 'tool run --help' for more information.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var level slog.Level
+			out := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Top: &Component{
+					Name:    "tool",
+					Default: "run",
+					Persistent: Flags{
+						{Type: BooleanFlag, Long: "chatty", Short: "v", Repeats: true, Verbosity: true},
+					},
+					Components: Components{
+						{
+							Name: "run",
+							Function: func(c *Component) Code {
+								level = c.Level()
+								return Success
+							},
+						},
+					},
+				},
+			}
+			New(config).Run()
+			must.Eq(t, tc.expOut, out.String())
+			must.Eq(t, tc.expLevel, level)
+		})
+	}
+}
+
+func TestConfiguration_Logger(t *testing.T) {
+	t.Parallel()
+
+	w := new(strings.Builder)
+	config := &Configuration{
+		Arguments: []string{"child", "--name", "bob", "--bogus"},
+		Output:    new(strings.Builder),
+		Logger: slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})),
+		Top: &Component{
+			Components: Components{
+				{
+					Name:  "child",
+					Flags: Flags{{Type: StringFlag, Long: "name"}},
+				},
+			},
+		},
+	}
+
+	result := New(config).Run()
+	must.Eq(t, Failure, result)
+
+	logs := w.String()
+	must.StrContains(t, logs, `msg="babycli: resolved subcommand" name=child`)
+	must.StrContains(t, logs, `msg="babycli: parsing flag" flag=name type=string`)
+	must.StrContains(t, logs, `msg="babycli: command failed" error="babycli: flag \"bogus\" is not defined" code=1`)
+	must.StrNotContains(t, logs, "bob")
+}
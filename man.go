@@ -0,0 +1,138 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GenManTree writes a roff man page documenting top and every subcommand
+// below it.
+func GenManTree(w io.Writer, top *Component) error {
+	bw := bufio.NewWriter(w)
+
+	title := strings.ToUpper(top.Name)
+	writef(bw, ".TH %s 1", roff(title))
+
+	_, _ = bw.WriteString(".SH NAME\n")
+	if top.Help != "" {
+		writef(bw, "%s \\- %s", roff(top.Name), roff(top.Help))
+	} else {
+		write(bw, roff(top.Name))
+	}
+
+	_, _ = bw.WriteString(".SH SYNOPSIS\n")
+	manSynopsis(bw, []string{top.Name}, top)
+
+	if top.Description != "" {
+		_, _ = bw.WriteString(".SH DESCRIPTION\n")
+		manDescription(bw, top.Description)
+	}
+
+	if len(top.Annotations) > 0 {
+		_, _ = bw.WriteString(".SH ANNOTATIONS\n")
+		manAnnotations(bw, top.Annotations)
+	}
+
+	if options := top.options().visible(); len(options) > 0 {
+		_, _ = bw.WriteString(".SH OPTIONS\n")
+		manFlags(bw, options)
+	}
+
+	if commands := top.Components.visible(); len(commands) > 0 {
+		_, _ = bw.WriteString(".SH COMMANDS\n")
+		for _, cmd := range commands {
+			manCommand(bw, []string{top.Name}, cmd)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func manCommand(w io.Writer, parent []string, c *Component) {
+	path := append(parent[:len(parent):len(parent)], c.Name)
+	writef(w, ".SS \"%s\"", roff(strings.Join(path, " ")))
+
+	if c.Help != "" {
+		write(w, roff(c.Help))
+	}
+
+	_, _ = io.WriteString(w, ".PP\n")
+	manSynopsis(w, path, c)
+
+	if c.Description != "" {
+		_, _ = io.WriteString(w, ".PP\n")
+		manDescription(w, c.Description)
+	}
+
+	if len(c.Annotations) > 0 {
+		_, _ = io.WriteString(w, ".PP\n")
+		manAnnotations(w, c.Annotations)
+	}
+
+	manFlags(w, c.options().visible())
+
+	for _, cmd := range c.Components.visible() {
+		manCommand(w, path, cmd)
+	}
+}
+
+func manSynopsis(w io.Writer, path []string, c *Component) {
+	writef(w, ".B %s", roff(strings.Join(path, " ")))
+	if args := c.arguments(); len(args) > 0 {
+		write(w, roff(strings.Join(args, " ")))
+	}
+}
+
+func manDescription(w io.Writer, description string) {
+	for _, line := range chop(description) {
+		if strings.TrimSpace(line) == "" {
+			_, _ = io.WriteString(w, ".PP\n")
+			continue
+		}
+		write(w, roff(line))
+	}
+}
+
+func manFlags(w io.Writer, flags Flags) {
+	for _, f := range flags {
+		_, _ = io.WriteString(w, ".TP\n")
+		var names []string
+		if f.Long != "" {
+			names = append(names, "\\fB"+roff("--"+f.Long)+"\\fR")
+		}
+		if f.Short != "" {
+			names = append(names, "\\fB"+roff("-"+f.Short)+"\\fR")
+		}
+		writef(w, "%s \\fI%s\\fR", strings.Join(names, ", "), roff(f.metavar()))
+		help := f.Help
+		if f.showDefault() {
+			help = f.help(nil)[2]
+		}
+		write(w, roff(help))
+		manAnnotations(w, f.Annotations)
+	}
+}
+
+// manAnnotations lists annotations by key, one to a line.
+func manAnnotations(w io.Writer, annotations map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		_, _ = io.WriteString(w, ".br\n")
+		writef(w, "\\fB%s\\fR: %s", roff(key), roff(annotations[key]))
+	}
+}
+
+// roff escapes s for use as text in a roff document.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
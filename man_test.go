@@ -0,0 +1,94 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestGenManTree(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name:        "tool",
+		Help:        "a useful tool",
+		Description: "The tool does things.",
+		Annotations: map[string]string{"area": "release"},
+		Flags: Flags{
+			{
+				Type:  StringFlag,
+				Long:  "region",
+				Short: "r",
+				Help:  "the region to use",
+			},
+		},
+		Components: Components{
+			{
+				Name: "deploy",
+				Help: "deploy things",
+				Components: Components{
+					{
+						Name:        "canary",
+						Help:        "deploy a canary",
+						Annotations: map[string]string{"owner": "platform"},
+						Flags: Flags{
+							{
+								Type:        IntFlag,
+								Long:        "percent",
+								Help:        "percent of traffic",
+								Default:     &Default{Value: 5, Show: true},
+								Annotations: map[string]string{"unit": "%", "max-value": "100"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	w := new(strings.Builder)
+	err := GenManTree(w, top)
+	must.NoError(t, err)
+
+	must.Eq(t, `.TH TOOL 1
+.SH NAME
+tool \- a useful tool
+.SH SYNOPSIS
+.B tool
+[options] <command>
+.SH DESCRIPTION
+The tool does things.
+.SH ANNOTATIONS
+.br
+\fBarea\fR: release
+.SH OPTIONS
+.TP
+\fB\-\-region\fR, \fB\-r\fR \fIstring\fR
+the region to use
+.SH COMMANDS
+.SS "tool deploy"
+deploy things
+.PP
+.B tool deploy
+<command>
+.SS "tool deploy canary"
+deploy a canary
+.PP
+.B tool deploy canary
+[options] [arguments...]
+.PP
+.br
+\fBowner\fR: platform
+.TP
+\fB\-\-percent\fR \fIinteger\fR
+percent of traffic (5)
+.br
+\fBmax\-value\fR: 100
+.br
+\fBunit\fR: %
+`, w.String())
+}
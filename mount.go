@@ -0,0 +1,19 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"slices"
+)
+
+// Mount returns the Top of c as a subcommand named name, so a tool can be
+// composed into another. The Globals of c become Persistent flags of the
+// subcommand, given after its name; the other settings of c are not used.
+// The tree of c is not modified.
+func Mount(name string, c *Configuration) *Component {
+	top := *c.Top
+	top.Name = name
+	top.Persistent = slices.Concat(c.Globals, top.Persistent)
+	return &top
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestMount(t *testing.T) {
+	t.Parallel()
+
+	deploy := &Configuration{
+		Globals: Flags{{Type: StringFlag, Long: "region", Default: &Default{Value: "us-east"}}},
+		Top: &Component{
+			Name: "deploy",
+			Components: Components{
+				{
+					Name: "apply",
+					Function: func(c *Component) Code {
+						writef(c.Stdout(), "apply %s %t", c.GetString("region"), c.GetBool("debug"))
+						return Success
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "default",
+			args:    []string{"infra", "apply"},
+			expCode: Success,
+			expOut:  "apply us-east false\n",
+		},
+		{
+			name:    "globals",
+			args:    []string{"--debug", "infra", "--region", "eu-west", "apply"},
+			expCode: Success,
+			expOut:  "apply eu-west true\n",
+		},
+		{
+			name:    "help",
+			args:    []string{"infra", "apply", "--help"},
+			expCode: Success,
+			expOut:  "NAME:\n  apply\n\nUSAGE:\n  tool infra apply [global options] [arguments...]\n\nGLOBALS:\n--region     string - \n--debug     boolean - \n--help/-h   boolean - print help message\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments: tc.args,
+				Output:    out,
+				Globals:   Flags{{Type: BooleanFlag, Long: "debug"}},
+				Top: &Component{
+					Name:       "tool",
+					Components: Components{Mount("infra", deploy)},
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+
+	must.Eq(t, "deploy", deploy.Top.Name)
+	must.Nil(t, deploy.Top.Persistent)
+}
@@ -0,0 +1,277 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+)
+
+var outputFlag = &Flag{
+	Type:    StringFlag,
+	Long:    "output",
+	Short:   "o",
+	Help:    "output format",
+	Choices: []string{FormatTable, FormatJSON, FormatYAML},
+	Default: &Default{Value: FormatTable, Show: true},
+}
+
+// Format returns the output format selected by the --output flag.
+func (c *Component) Format() string {
+	if _, exists := c.lookup(outputFlag.Long); !exists {
+		return FormatTable
+	}
+	return c.GetString(outputFlag.Long)
+}
+
+// Emit writes v to Stdout in the format selected by the --output flag.
+func (c *Component) Emit(v any) error {
+	switch format := c.Format(); format {
+	case FormatJSON:
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYAML:
+		return emitYAML(c.stdout, v)
+	case FormatTable:
+		headers, rows := tabulate(v)
+		c.Table(headers, rows)
+		return nil
+	default:
+		return fmt.Errorf("babycli: output format %q is not supported", format)
+	}
+}
+
+// emitYAML writes v as YAML, by way of its JSON representation.
+func emitYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var generic any
+	if err = decoder.Decode(&generic); err != nil {
+		return err
+	}
+
+	sb := new(strings.Builder)
+	yamlValue(sb, generic, 0)
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func yamlValue(sb *strings.Builder, v any, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	switch value := v.(type) {
+	case map[string]any:
+		if len(value) == 0 {
+			sb.WriteString(prefix + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			sb.WriteString(prefix + yamlScalar(key) + ":")
+			yamlNested(sb, value[key], indent)
+		}
+	case []any:
+		if len(value) == 0 {
+			sb.WriteString(prefix + "[]\n")
+			return
+		}
+		for _, item := range value {
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				// place the first key of a mapping on the line of its marker
+				nested := new(strings.Builder)
+				yamlValue(nested, m, indent+1)
+				sb.WriteString(prefix + "- ")
+				sb.WriteString(strings.TrimPrefix(nested.String(), prefix+"  "))
+				continue
+			}
+			sb.WriteString(prefix + "-")
+			yamlNested(sb, item, indent)
+		}
+	default:
+		sb.WriteString(prefix + yamlScalar(value) + "\n")
+	}
+}
+
+// yamlNested writes v following a key or list marker at the given indent.
+func yamlNested(sb *strings.Builder, v any, indent int) {
+	switch value := v.(type) {
+	case map[string]any:
+		if len(value) == 0 {
+			sb.WriteString(" {}\n")
+			return
+		}
+		sb.WriteString("\n")
+		yamlValue(sb, value, indent+1)
+	case []any:
+		if len(value) == 0 {
+			sb.WriteString(" []\n")
+			return
+		}
+		sb.WriteString("\n")
+		yamlValue(sb, value, indent+1)
+	default:
+		sb.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+var (
+	yamlPlain    = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*$`)
+	yamlReserved = []string{"true", "false", "yes", "no", "on", "off", "null", "y", "n"}
+)
+
+func yamlScalar(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case string:
+		if yamlPlain.MatchString(value) && !slices.Contains(yamlReserved, strings.ToLower(value)) {
+			return value
+		}
+		return strconv.Quote(value)
+	default:
+		return strconv.Quote(fmt.Sprint(value))
+	}
+}
+
+// tabulate converts v into table headers and rows. Slices of structs or maps
+// become one row per element, and anything else a single row.
+func tabulate(v any) ([]string, [][]string) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	var items []reflect.Value
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i))
+		}
+	default:
+		items = []reflect.Value{value}
+	}
+
+	var headers []string
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+			item = item.Elem()
+		}
+		keys, cells := cellsOf(item)
+		if headers == nil {
+			headers = keys
+		}
+		rows = append(rows, cells)
+	}
+
+	return headers, rows
+}
+
+func cellsOf(item reflect.Value) ([]string, []string) {
+	switch item.Kind() {
+	case reflect.Struct:
+		var keys, cells []string
+		t := item.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			keys = append(keys, strings.ToUpper(name))
+			cells = append(cells, fmt.Sprint(item.Field(i).Interface()))
+		}
+		return keys, cells
+	case reflect.Map:
+		mapKeys := item.MapKeys()
+		slices.SortFunc(mapKeys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		keys := make([]string, 0, len(mapKeys))
+		cells := make([]string, 0, len(mapKeys))
+		for _, key := range mapKeys {
+			keys = append(keys, strings.ToUpper(fmt.Sprint(key.Interface())))
+			cells = append(cells, fmt.Sprint(item.MapIndex(key).Interface()))
+		}
+		return keys, cells
+	case reflect.Invalid:
+		return []string{"VALUE"}, []string{""}
+	default:
+		return []string{"VALUE"}, []string{fmt.Sprint(item.Interface())}
+	}
+}
+
+// Table writes headers and rows to Stdout as columns aligned with spaces.
+// The last column is wrapped to fit within the width of the terminal.
+func (c *Component) Table(headers []string, rows [][]string) {
+	table(c.stdout, headers, rows, c.style.cols())
+}
+
+func table(w io.Writer, headers []string, rows [][]string, width int) {
+	columns := len(headers)
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+
+	widths := make([]int, columns)
+	for _, row := range append([][]string{headers}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	line := func(cells []string) {
+		indent := 0
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				writeWrapped(w, cell, indent, width)
+				break
+			}
+			_, _ = io.WriteString(w, rightPad(widths[i], cell))
+			_, _ = io.WriteString(w, " ")
+			indent += widths[i] + 2
+		}
+		_, _ = io.WriteString(w, "\n")
+	}
+
+	if len(headers) > 0 {
+		line(headers)
+	}
+	for _, row := range rows {
+		line(row)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+type server struct {
+	Name    string   `json:"name"`
+	Port    int      `json:"port"`
+	Healthy bool     `json:"healthy"`
+	Tags    []string `json:"tags"`
+	secret  string
+}
+
+var servers = []server{
+	{Name: "alpha", Port: 8080, Healthy: true, Tags: []string{"web"}},
+	{Name: "beta-two", Port: 443, Healthy: false, Tags: nil, secret: "hidden"},
+}
+
+func TestComponent_Emit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		exp  string
+	}{
+		{
+			name: "table default",
+			args: nil,
+			exp: `NAME      PORT  HEALTHY  TAGS
+alpha     8080  true     [web]
+beta-two  443   false    []
+`,
+		},
+		{
+			name: "json",
+			args: []string{"--output", "json"},
+			exp: `[
+  {
+    "name": "alpha",
+    "port": 8080,
+    "healthy": true,
+    "tags": [
+      "web"
+    ]
+  },
+  {
+    "name": "beta-two",
+    "port": 443,
+    "healthy": false,
+    "tags": null
+  }
+]
+`,
+		},
+		{
+			name: "yaml",
+			args: []string{"-o", "yaml"},
+			exp: `- healthy: true
+  name: alpha
+  port: 8080
+  tags:
+    - web
+- healthy: false
+  name: beta-two
+  port: 443
+  tags: null
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Stdout:    stdout,
+				Formats:   true,
+				Top: &Component{
+					FunctionE: func(c *Component) error {
+						return c.Emit(servers)
+					},
+				},
+			}
+			result := New(config).Run()
+			must.Eq(t, Success, result)
+			must.Eq(t, tc.exp, stdout.String())
+		})
+	}
+}
+
+func TestEmitYAML(t *testing.T) {
+	t.Parallel()
+
+	w := new(strings.Builder)
+	err := emitYAML(w, map[string]any{
+		"empty":  map[string]any{},
+		"list":   []any{},
+		"quoted": "yes",
+		"spaced": "hello world",
+		"number": "42",
+		"nested": map[string]any{"a": 1.5, "b": nil},
+	})
+	must.NoError(t, err)
+	must.Eq(t, `empty: {}
+list: []
+nested:
+  a: 1.5
+  b: null
+number: "42"
+quoted: "yes"
+spaced: "hello world"
+`, w.String())
+}
+
+func TestTabulate(t *testing.T) {
+	t.Parallel()
+
+	headers, rows := tabulate(map[int]string{2: "two", 1: "one"})
+	must.Eq(t, []string{"1", "2"}, headers)
+	must.Eq(t, [][]string{{"one", "two"}}, rows)
+
+	headers, rows = tabulate([]string{"a", "b"})
+	must.Eq(t, []string{"VALUE"}, headers)
+	must.Eq(t, [][]string{{"a"}, {"b"}}, rows)
+}
+
+func TestComponent_Table(t *testing.T) {
+	t.Parallel()
+
+	stdout := new(strings.Builder)
+	config := &Configuration{
+		Stdout: stdout,
+		Width:  36,
+		Top: &Component{
+			Function: func(c *Component) Code {
+				c.Table(
+					[]string{"ID", "NAME", "DESCRIPTION"},
+					[][]string{
+						{"1", "alpha", "the first letter"},
+						{"22", "beta", "the second letter of the greek alphabet"},
+					},
+				)
+				return Success
+			},
+		},
+	}
+
+	result := New(config).Run()
+	must.Eq(t, Success, result)
+	must.Eq(t, `ID  NAME   DESCRIPTION
+1   alpha  the first letter
+22  beta   the second letter of the
+           greek alphabet
+`, stdout.String())
+}
@@ -0,0 +1,53 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultPager = "less"
+
+func (c *Component) printHelp(output io.Writer) {
+	text := c.help()
+	if c.helpAllSet() {
+		text = c.tree()
+	}
+	if c.style != nil && c.style.pager && page(output, text, c.getenv) {
+		return
+	}
+	write(output, text)
+}
+
+// page pipes text through the pager if output is a terminal too short to
+// show all of text, returning whether the pager was used.
+func page(output io.Writer, text string, getenv func(string) string) bool {
+	f, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+
+	_, height, ok := terminalSize(f)
+	if !ok || strings.Count(text, "\n")+1 < height {
+		return false
+	}
+
+	args := strings.Fields(getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{defaultPager}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text + "\n")
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	_ = cmd.Wait()
+	return true
+}
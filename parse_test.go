@@ -0,0 +1,138 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func parseConfig(args []string) *Configuration {
+	return &Configuration{
+		Arguments: args,
+		Globals:   Flags{{Type: BooleanFlag, Long: "debug", Short: "d"}},
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "deploy",
+					Flags: Flags{
+						{Type: StringFlag, Long: "env", Short: "e", Choices: []string{"dev", "prod"}},
+						{Type: IntFlag, Long: "replicas", Repeats: true},
+						{Type: DurationFlag, Long: "wait"},
+						{Type: SecretFlag, Long: "token"},
+					},
+					Function: func(*Component) Code { return Success },
+				},
+				{
+					Name: "config",
+					Components: Components{
+						{Name: "get", Function: func(*Component) Code { return Success }},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	leaf, err := Parse(parseConfig([]string{"deploy", "-e", "dev", "--replicas=2"}))
+	must.NoError(t, err)
+	must.Eq(t, "dev", leaf.GetString("env"))
+
+	_, err = Parse(parseConfig([]string{"deploy", "-e", "staging"}))
+	must.ErrorIs(t, err, ErrBadValue)
+
+	_, err = Parse(&Configuration{})
+	must.Error(t, err)
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"deploy --env prod --replicas 3 --replicas 4 --wait 1m",
+		"deploy -e=dev --token=x -- extra",
+		"config get --help",
+		"help config get",
+		"-d deploy '-e=dev'",
+		"- -- --- -=",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, line string) {
+		leaf, err := Parse(parseConfig(strings.Fields(line)))
+		if err == nil {
+			must.NotNil(t, leaf)
+		}
+	})
+}
+
+func TestParse_argumentsEnv(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		env      string
+		args     []string
+		expDebug bool
+		expEnv   string
+		expErr   string
+	}{
+		{
+			name:   "unset",
+			args:   []string{"deploy", "-e", "dev"},
+			expEnv: "dev",
+		},
+		{
+			name:     "prepended",
+			env:      " -d ",
+			args:     []string{"deploy", "-e", "dev"},
+			expDebug: true,
+			expEnv:   "dev",
+		},
+		{
+			name:   "overridden",
+			env:    "deploy --env 'prod'",
+			args:   []string{"--env", "dev"},
+			expEnv: "dev",
+		},
+		{
+			name:   "invalid",
+			env:    `-d "`,
+			args:   []string{"deploy"},
+			expErr: "babycli: unable to split TOOL_OPTS: unterminated quote",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := parseConfig(tc.args)
+			config.ArgumentsEnv = "TOOL_OPTS"
+			config.Duplicates = DuplicatesLastWins
+			config.Getenv = func(key string) string {
+				if key == "TOOL_OPTS" {
+					return tc.env
+				}
+				return ""
+			}
+
+			leaf, err := Parse(config)
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				must.ErrorIs(t, err, ErrBadValue)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expDebug, leaf.GetBool("debug"))
+			must.Eq(t, tc.expEnv, leaf.GetString("env"))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+func (c *Component) plugin(sub string) (string, bool) {
+	if c.plugins == "" {
+		return "", false
+	}
+	path, err := exec.LookPath(c.plugins + "-" + sub)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func (c *Component) exec(path string) *result {
+	return &result{code: c.Exec(c.context, path, c.forwarded()...)}
+}
+
+// Exec runs the program name with args, connected to the standard streams of
+// c, and returns its exit code. If the program cannot be run, or is killed by
+// a signal, an error is written to Stderr and Failure is returned.
+func (c *Component) Exec(ctx context.Context, name string, args ...string) Code {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return Success
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return exitErr.ExitCode()
+	default:
+		writef(c.stderr, "babycli: unable to run %q: %v", name, err)
+		return Failure
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRun_plugin(t *testing.T) { //nolint:paralleltest // modifies PATH
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := "#!/bin/sh\necho \"$@\" > " + out + "\nexit 3\n"
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "tool-hello"), []byte(script), 0o755))
+	t.Setenv("PATH", dir)
+
+	config := &Configuration{
+		Arguments: []string{"hello", "one", "--two"},
+		Plugins:   "tool",
+		Top: &Component{
+			Components: Components{
+				{
+					Name: "about",
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Eq(t, 3, result)
+
+	b, err := os.ReadFile(out)
+	must.NoError(t, err)
+	must.Eq(t, "one --two\n", string(b))
+}
+
+func TestComponent_Exec(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	cases := []struct {
+		name      string
+		args      []string
+		expCode   Code
+		expStdout string
+		expStderr string
+	}{
+		{
+			name:      "success",
+			args:      []string{"sh", "-c", "echo $0 $1", "one", "two"},
+			expCode:   Success,
+			expStdout: "one two\n",
+		},
+		{
+			name:      "exit code",
+			args:      []string{"sh", "-c", "echo failed >&2; exit 4"},
+			expCode:   4,
+			expStderr: "failed\n",
+		},
+		{
+			name:      "not found",
+			args:      []string{"babycli-does-not-exist"},
+			expCode:   Failure,
+			expStderr: `babycli: unable to run "babycli-does-not-exist": exec: "babycli-does-not-exist": executable file not found in $PATH` + "\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			config := &Configuration{
+				Arguments: tc.args,
+				Stdout:    stdout,
+				Stderr:    stderr,
+				Top: &Component{
+					Function: func(c *Component) Code {
+						args := c.Arguments()
+						return c.Exec(c.Context(), args[0], args[1:]...)
+					},
+				},
+			}
+
+			must.Eq(t, tc.expCode, New(config).Run())
+			must.Eq(t, tc.expStdout, stdout.String())
+			must.Eq(t, tc.expStderr, stderr.String())
+		})
+	}
+}
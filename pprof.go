@@ -0,0 +1,110 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+)
+
+var (
+	cpuProfileFlag = &Flag{
+		Type:        StringFlag,
+		Long:        "cpuprofile",
+		Placeholder: "file",
+		Help:        "write a CPU profile of the command to file",
+		Hidden:      true,
+	}
+
+	memProfileFlag = &Flag{
+		Type:        StringFlag,
+		Long:        "memprofile",
+		Placeholder: "file",
+		Help:        "write a memory profile to file after the command",
+		Hidden:      true,
+	}
+
+	traceFlag = &Flag{
+		Type:        StringFlag,
+		Long:        "trace",
+		Placeholder: "file",
+		Help:        "write an execution trace of the command to file",
+		Hidden:      true,
+	}
+)
+
+// builtinString returns the last value given for the built-in flag f, if it
+// is enabled.
+func (c *Component) builtinString(f *Flag) string {
+	values := c.vals.strings[f.Long]
+	if !slices.Contains(c.globals, f) || len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// startProfiling starts the CPU profile and execution trace requested by the
+// built-in flags. Stopping them, and writing the memory profile, is left to
+// the cleanups of the run.
+func (c *Component) startProfiling() error {
+	if path := c.builtinString(cpuProfileFlag); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("babycli: unable to create cpu profile: %w", err)
+		}
+		if err = pprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("babycli: unable to start cpu profile: %w", err)
+		}
+		c.OnCleanup(func() {
+			pprof.StopCPUProfile()
+			c.printError(f.Close())
+		})
+	}
+
+	if path := c.builtinString(traceFlag); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("babycli: unable to create trace: %w", err)
+		}
+		if err = trace.Start(f); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("babycli: unable to start trace: %w", err)
+		}
+		c.OnCleanup(func() {
+			trace.Stop()
+			c.printError(f.Close())
+		})
+	}
+
+	if path := c.builtinString(memProfileFlag); path != "" {
+		c.OnCleanup(func() {
+			c.printError(writeHeapProfile(path))
+		})
+	}
+
+	return nil
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("babycli: unable to create memory profile: %w", err)
+	}
+	runtime.GC()
+	err = pprof.WriteHeapProfile(f)
+	return errors.Join(err, f.Close())
+}
+
+// printError writes err to Stderr, if it is not nil.
+func (c *Component) printError(err error) {
+	if err != nil {
+		write(c.stderr, err.Error())
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRun_pprof(t *testing.T) { //nolint:paralleltest // starts the process wide profilers
+	dir := t.TempDir()
+	cpu := filepath.Join(dir, "cpu.pprof")
+	mem := filepath.Join(dir, "mem.pprof")
+	trace := filepath.Join(dir, "trace.out")
+
+	ran := false
+	stderr := new(strings.Builder)
+	code := New(&Configuration{
+		Arguments: []string{"--cpuprofile", cpu, "--memprofile=" + mem, "--trace", trace},
+		Top: &Component{
+			Name: "tool",
+			Function: func(*Component) Code {
+				ran = true
+				return Success
+			},
+		},
+		Pprof:  true,
+		Stdout: new(strings.Builder),
+		Stderr: stderr,
+	}).Run()
+	must.Eq(t, Success, code)
+	must.True(t, ran)
+	must.Eq(t, "", stderr.String())
+
+	for _, path := range []string{cpu, mem, trace} {
+		info, err := os.Stat(path)
+		must.NoError(t, err)
+		must.Positive(t, info.Size())
+	}
+}
+
+func TestRun_pprofInvalid(t *testing.T) {
+	t.Parallel()
+
+	ran := false
+	stderr := new(strings.Builder)
+	code := New(&Configuration{
+		Arguments: []string{"--memprofile", filepath.Join(t.TempDir(), "missing", "mem.pprof")},
+		Top: &Component{
+			Name: "tool",
+			Function: func(*Component) Code {
+				ran = true
+				return Success
+			},
+		},
+		Pprof:  true,
+		Stdout: new(strings.Builder),
+		Stderr: stderr,
+	}).Run()
+	must.Eq(t, Success, code)
+	must.True(t, ran)
+	must.StrHasPrefix(t, "babycli: unable to create memory profile: ", stderr.String())
+}
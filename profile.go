@@ -0,0 +1,63 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+var profileFlag = &Flag{
+	Type:   BooleanFlag,
+	Long:   "profile",
+	Help:   "print the time taken by each step of the command on exit",
+	Hidden: true,
+}
+
+// step is the time taken by part of a run.
+type step struct {
+	name string
+	took time.Duration
+}
+
+// profile records the time taken by the parsing, hooks, and Function of a run.
+type profile struct {
+	steps []step
+}
+
+// measure records the time since start as the step name, if the run is
+// profiled.
+func (c *Component) measure(name string, start time.Time) {
+	if c.profile == nil {
+		return
+	}
+	c.profile.steps = append(c.profile.steps, step{name: name, took: time.Since(start)})
+}
+
+// printProfile writes the steps of the run started at start to Stderr, if
+// --profile was given.
+func (r *Runnable) printProfile(start time.Time) {
+	root := r.root
+	if root.profile == nil || !slices.Contains(root.vals.bools[profileFlag.Long], true) {
+		return
+	}
+
+	steps := append(root.profile.steps, step{name: "total", took: time.Since(start)})
+	width := 0
+	for _, s := range steps {
+		width = max(width, len(s.name))
+	}
+
+	sb := new(strings.Builder)
+	root.heading(sb, "PROFILE")
+	for _, s := range steps {
+		sb.WriteString(tab)
+		sb.WriteString(rightPad(width, s.name))
+		sb.WriteString(s.took.String())
+		sb.WriteString("\n")
+	}
+	_, _ = io.WriteString(root.stderr, sb.String())
+}
@@ -0,0 +1,91 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRun_profile(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name:   "tool",
+		Before: func(*Component) Code { return Success },
+		Components: Components{
+			{
+				Name:     "deploy",
+				Function: func(*Component) Code { return Success },
+				After:    func(*Component) Code { return Success },
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		expSteps []string
+	}{
+		{
+			name:     "profiled",
+			args:     []string{"deploy", "--profile"},
+			expSteps: []string{"parse", "before tool", "function", "after tool deploy", "total"},
+		},
+		{
+			name:     "invalid",
+			args:     []string{"--profile", "destroy"},
+			expSteps: []string{"parse", "total"},
+		},
+		{
+			name: "not profiled",
+			args: []string{"deploy"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := new(strings.Builder)
+			_ = New(&Configuration{
+				Arguments: tc.args,
+				Top:       top,
+				Profile:   true,
+				Stdout:    new(strings.Builder),
+				Stderr:    stderr,
+			}).Run()
+
+			_, profile, found := strings.Cut(stderr.String(), "PROFILE:\n")
+			must.Eq(t, len(tc.expSteps) > 0, found)
+
+			var steps []string
+			line := regexp.MustCompile(`^  (.+?) +\d[\w.µ]*s$`)
+			for _, s := range strings.Split(strings.TrimSuffix(profile, "\n"), "\n") {
+				if m := line.FindStringSubmatch(s); m != nil {
+					steps = append(steps, m[1])
+				}
+			}
+			must.Eq(t, tc.expSteps, steps)
+		})
+	}
+}
+
+func TestRun_profileHidden(t *testing.T) {
+	t.Parallel()
+
+	out := new(strings.Builder)
+	code := New(&Configuration{
+		Arguments: []string{"--help"},
+		Top:       &Component{Name: "tool", Function: func(*Component) Code { return Success }},
+		Profile:   true,
+		Output:    out,
+	}).Run()
+	must.Eq(t, Success, code)
+	must.False(t, strings.Contains(out.String(), "profile"))
+	must.False(t, strings.Contains(out.String(), "[global options]"))
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Reporter receives a record of each run, for metrics or analytics, such as
+// exporting them through OpenTelemetry.
+type Reporter interface {
+	Report(invocation *Invocation)
+}
+
+// Invocation describes a completed run. It holds no flag values or
+// arguments, which may be sensitive.
+type Invocation struct {
+	// Path is the names of the commands run, from the top down. For invalid
+	// arguments it is the command whose arguments were not valid.
+	Path []string
+
+	// Flags are the names of the flags given, sorted.
+	Flags []string
+
+	Duration time.Duration
+	Code     Code
+}
+
+// resolved returns the component the arguments resolved to, or for invalid
+// arguments the component whose arguments were not valid.
+func (r *Runnable) resolved() *Component {
+	if r.leaf != nil {
+		return r.leaf
+	}
+	var perr *ParseError
+	if errors.As(r.err, &perr) && perr.component != nil {
+		return perr.component
+	}
+	return r.root
+}
+
+// report sends the record of the run started at start to the Reporter.
+func (r *Runnable) report(start time.Time, code Code) {
+	if r.reporter == nil {
+		return
+	}
+
+	flags := make([]string, 0, len(r.root.vals.given))
+	for name := range r.root.vals.given {
+		flags = append(flags, name)
+	}
+	slices.Sort(flags)
+
+	r.reporter.Report(&Invocation{
+		Path:     r.resolved().path(),
+		Flags:    flags,
+		Duration: time.Since(start),
+		Code:     code,
+	})
+}
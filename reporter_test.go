@@ -0,0 +1,91 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+type invocations []*Invocation
+
+func (is *invocations) Report(i *Invocation) {
+	*is = append(*is, i)
+}
+
+func TestRun_reporter(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expPath  []string
+		expFlags []string
+		expCode  Code
+	}{
+		{
+			name:     "success",
+			args:     []string{"-d", "deploy", "--wait=1s", "-e", "dev"},
+			expPath:  []string{"tool", "deploy"},
+			expFlags: []string{"debug", "env", "wait"},
+			expCode:  Success,
+		},
+		{
+			name:     "failure",
+			args:     []string{"config", "get"},
+			expPath:  []string{"tool", "config", "get"},
+			expFlags: []string{},
+			expCode:  Failure,
+		},
+		{
+			name:     "invalid",
+			args:     []string{"deploy", "--env", "staging"},
+			expPath:  []string{"tool", "deploy"},
+			expFlags: []string{"env"},
+			expCode:  Failure,
+		},
+		{
+			name:     "unknown",
+			args:     []string{"destroy"},
+			expPath:  []string{"tool"},
+			expFlags: []string{},
+			expCode:  Failure,
+		},
+		{
+			name:     "panic",
+			args:     []string{"deploy", "--replicas", "1", "--replicas", "2"},
+			expPath:  []string{"tool", "deploy"},
+			expFlags: []string{"replicas"},
+			expCode:  Failure,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reported invocations
+			config := parseConfig(tc.args)
+			config.Output = io.Discard
+			config.Reporter = &reported
+			config.Top.Components[0].Function = func(c *Component) Code {
+				_ = c.GetInt("replicas")
+				return Success
+			}
+			config.Top.Components[1].Components[0].Function = func(*Component) Code {
+				return Failure
+			}
+
+			code := New(config).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Len(t, 1, reported)
+			must.Eq(t, tc.expPath, reported[0].Path)
+			must.Eq(t, tc.expFlags, reported[0].Flags)
+			must.Eq(t, tc.expCode, reported[0].Code)
+			must.Positive(t, reported[0].Duration)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Backoff configures how Retry retries a failed Function.
+type Backoff struct {
+	// Attempts is the maximum number of calls, including the first (default 3).
+	Attempts int
+
+	// Delay is the wait before the first retry (default 100ms). Each later
+	// wait is Multiplier times longer, up to MaxDelay.
+	Delay time.Duration
+
+	// Multiplier grows the wait between retries (default 2).
+	Multiplier float64
+
+	// MaxDelay limits the wait between retries (default 10s).
+	MaxDelay time.Duration
+
+	// Retryable reports whether a call failing with err should be retried.
+	// By default every error is, except those wrapped with UsageError.
+	Retryable func(err error) bool
+}
+
+const (
+	defaultAttempts   = 3
+	defaultDelay      = 100 * time.Millisecond
+	defaultMultiplier = 2
+	defaultMaxDelay   = 10 * time.Second
+)
+
+func (b *Backoff) attempts() int {
+	if b == nil || b.Attempts <= 0 {
+		return defaultAttempts
+	}
+	return b.Attempts
+}
+
+// delay returns the wait before retry number n, starting at zero.
+func (b *Backoff) delay(n int) time.Duration {
+	delay, multiplier, limit := defaultDelay, float64(defaultMultiplier), defaultMaxDelay
+	if b != nil && b.Delay > 0 {
+		delay = b.Delay
+	}
+	if b != nil && b.Multiplier >= 1 {
+		multiplier = b.Multiplier
+	}
+	if b != nil && b.MaxDelay > 0 {
+		limit = b.MaxDelay
+	}
+
+	d := float64(delay)
+	for i := 0; i < n && d < float64(limit); i++ {
+		d *= multiplier
+	}
+	return min(time.Duration(d), limit)
+}
+
+func (b *Backoff) retryable(err error) bool {
+	if b != nil && b.Retryable != nil {
+		return b.Retryable(err)
+	}
+	var usage *usageError
+	return !errors.As(err, &usage)
+}
+
+// Retry returns a FuncE calling f until it succeeds, retrying failures with
+// the exponential backoff of b, which may be nil for the defaults. Retries
+// stop when the context of the command is done.
+func Retry(b *Backoff, f FuncE) FuncE {
+	return func(c *Component) error {
+		ctx := c.Context()
+		for n := 0; ; n++ {
+			err := f(c)
+			if err == nil || n+1 >= b.attempts() || ctx.Err() != nil || !b.retryable(err) {
+				return err
+			}
+
+			delay := b.delay(n)
+			c.logger.Debug("babycli: retrying command", "name", c.Name, "error", err, "delay", delay)
+			if !sleep(ctx, delay) {
+				return err
+			}
+		}
+	}
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	flaky := errors.New("connection reset")
+	permanent := errors.New("permission denied")
+
+	cases := []struct {
+		name     string
+		backoff  *Backoff
+		failures []error
+		expCalls int
+		expCode  Code
+	}{
+		{
+			name:     "succeeds",
+			backoff:  &Backoff{Delay: time.Millisecond},
+			expCalls: 1,
+			expCode:  Success,
+		},
+		{
+			name:     "recovers",
+			backoff:  &Backoff{Delay: time.Millisecond},
+			failures: []error{flaky, flaky},
+			expCalls: 3,
+			expCode:  Success,
+		},
+		{
+			name:     "gives up",
+			backoff:  &Backoff{Attempts: 2, Delay: time.Millisecond},
+			failures: []error{flaky, flaky, flaky},
+			expCalls: 2,
+			expCode:  Failure,
+		},
+		{
+			name:     "usage error",
+			backoff:  &Backoff{Delay: time.Millisecond},
+			failures: []error{UsageError(flaky)},
+			expCalls: 1,
+			expCode:  Failure,
+		},
+		{
+			name: "not retryable",
+			backoff: &Backoff{
+				Delay:     time.Millisecond,
+				Retryable: func(err error) bool { return errors.Is(err, flaky) },
+			},
+			failures: []error{flaky, permanent, flaky},
+			expCalls: 2,
+			expCode:  Failure,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			code := New(&Configuration{
+				Output: io.Discard,
+				Top: &Component{
+					Name: "push",
+					FunctionE: Retry(tc.backoff, func(*Component) error {
+						calls++
+						if calls <= len(tc.failures) {
+							return tc.failures[calls-1]
+						}
+						return nil
+					}),
+				},
+			}).Run()
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expCalls, calls)
+		})
+	}
+}
+
+func TestRetry_cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	code := New(&Configuration{
+		Context: ctx,
+		Output:  io.Discard,
+		Top: &Component{
+			Name: "push",
+			FunctionE: Retry(&Backoff{Attempts: 10, Delay: time.Hour}, func(*Component) error {
+				calls++
+				cancel()
+				return errors.New("connection reset")
+			}),
+		},
+	}).Run()
+	must.Eq(t, Failure, code)
+	must.Eq(t, 1, calls)
+}
+
+func TestBackoff_delay(t *testing.T) {
+	t.Parallel()
+
+	var defaults *Backoff
+	must.Eq(t, 100*time.Millisecond, defaults.delay(0))
+	must.Eq(t, 400*time.Millisecond, defaults.delay(2))
+	must.Eq(t, 10*time.Second, defaults.delay(50))
+
+	b := &Backoff{Delay: time.Second, Multiplier: 3, MaxDelay: 5 * time.Second}
+	must.Eq(t, time.Second, b.delay(0))
+	must.Eq(t, 3*time.Second, b.delay(1))
+	must.Eq(t, 5*time.Second, b.delay(2))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const mask = "********"
+
+// secret is a string which is masked when formatted.
+type secret string
+
+func (s secret) String() string {
+	return mask
+}
+
+func (s secret) GoString() string {
+	return mask
+}
+
+func (c *Component) consumeSecretFlag(flag *Flag) error {
+	identity := flag.Identity()
+	value, err := c.value(flag)
+	if err != nil {
+		return err
+	}
+	record(&c.vals.secrets, identity, secret(value))
+	return nil
+}
+
+func (c *Component) HasSecret(flag string) bool {
+	return c.vals.secretCount(flag) > 0
+}
+
+// GetSecret returns the value of the secret flag. If the flag was not set
+// and has no default, the value is read from standard input with terminal
+// echo disabled.
+func (c *Component) GetSecret(flag string) string {
+	switch c.vals.secretCount(flag) {
+	case 0:
+		f := c.flagNamed(flag)
+		if f.Default != nil {
+			return f.Default.value().(string)
+		}
+		value, err := readSecret(c.stdin, c.stderr, fmt.Sprintf("%s: ", f.Identity()))
+		if err != nil {
+			panicf("unable to read value for secret flag %q: %v", flag, err)
+		}
+		if value == "" && f.Require {
+			panicf("no value for secret flag %q", flag)
+		}
+		record(&c.vals.secrets, flag, secret(value))
+		return value
+	case 1:
+		return string(c.vals.secrets[flag][0])
+	default:
+		panicf("multiple values set for secret flag %q", flag)
+	}
+	return ""
+}
+
+// readSecret writes prompt to output and reads a line from input, without
+// echoing the input if it is a terminal. Nothing past the line is read, so the
+// input after it is left for later prompts and reads.
+func readSecret(input io.Reader, output io.Writer, prompt string) (string, error) {
+	_, _ = io.WriteString(output, prompt)
+
+	if f, ok := input.(*os.File); ok {
+		if restore, err := disableEcho(f); err == nil {
+			defer func() {
+				restore()
+				_, _ = io.WriteString(output, "\n")
+			}()
+		}
+	}
+
+	return unbuffered{input}.readLine()
+}
@@ -0,0 +1,116 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestComponent_GetSecret(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		args    []string
+		def     *Default
+		expText string
+	}{
+		{
+			name:    "provided",
+			args:    []string{"--token", "abc123"},
+			expText: "token is abc123",
+		},
+		{
+			name:    "default",
+			args:    nil,
+			def:     &Default{Value: "xyz789", Show: true},
+			expText: "token is xyz789",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var output string
+			config := &Configuration{
+				Arguments: tc.args,
+				Top: &Component{
+					Flags: Flags{
+						{
+							Type:    SecretFlag,
+							Long:    "token",
+							Default: tc.def,
+						},
+					},
+					Function: func(c *Component) Code {
+						output = "token is " + c.GetSecret("token")
+						return Success
+					},
+				},
+			}
+			result := New(config).Run()
+			must.Eq(t, Success, result)
+			must.Eq(t, tc.expText, output)
+		})
+	}
+}
+
+func TestFlag_secret_help(t *testing.T) {
+	t.Parallel()
+
+	f := &Flag{
+		Type:    SecretFlag,
+		Long:    "token",
+		Help:    "the api token",
+		Default: &Default{Value: "xyz789", Show: true},
+	}
+	must.Eq(t, [3]string{"--token", "secret", "the api token"}, f.help(nil))
+}
+
+func TestSecret_format(t *testing.T) {
+	t.Parallel()
+
+	s := secret("abc123")
+	must.Eq(t, "********", fmt.Sprintf("%v", s))
+	must.Eq(t, "********", fmt.Sprintf("%#v", s))
+	must.Eq(t, "[********]", fmt.Sprint([]secret{s}))
+}
+
+func TestReadSecret(t *testing.T) {
+	t.Parallel()
+
+	w := new(strings.Builder)
+	value, err := readSecret(strings.NewReader("hunter2\n"), w, "token: ")
+	must.NoError(t, err)
+	must.Eq(t, "hunter2", value)
+	must.Eq(t, "token: ", w.String())
+}
+
+func TestComponent_GetSecret_stdin(t *testing.T) {
+	t.Parallel()
+
+	var output string
+	config := &Configuration{
+		Stdin:  strings.NewReader("hunter2\nswordfish\nrest of input\n"),
+		Stderr: io.Discard,
+		Top: &Component{
+			Flags: Flags{
+				{Type: SecretFlag, Long: "user-token"},
+				{Type: SecretFlag, Long: "admin-token"},
+			},
+			FunctionE: func(c *Component) error {
+				user, admin := c.GetSecret("user-token"), c.GetSecret("admin-token")
+				rest, err := io.ReadAll(c.Stdin())
+				output = fmt.Sprintf("%s %s %q", user, admin, rest)
+				return err
+			},
+		},
+	}
+	must.Eq(t, Success, New(config).Run())
+	must.Eq(t, `hunter2 swordfish "rest of input\n"`, output)
+}
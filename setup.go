@@ -5,27 +5,87 @@ package babycli
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"os"
+	"os/signal"
+	"runtime/debug"
 	"slices"
-
-	"noxide.lol/go/stacks"
+	"strings"
+	"time"
 )
 
 type Code = int
 
 const (
-	Success   Code = 0
-	Failure   Code = 1
+	Success Code = 0
+	Failure Code = 1
+
+	// Usability returned by a Function prints the help of the command, and
+	// exits with the code for usage errors.
+	//
+	// Deprecated: return an error wrapped with UsageError from FunctionE.
 	Usability Code = math.MaxInt
 )
 
+type kind uint8
+
+const (
+	otherKind kind = iota
+	usageKind
+	runtimeKind
+	helpKind
+	crashKind
+)
+
 type result struct {
 	code Code
+	err  error
+	kind kind
+}
+
+// ExitCodes maps categories of outcomes to exit codes. A zero value field
+// leaves the default exit code of that category in place.
+type ExitCodes struct {
+	// Usage is returned for invalid arguments, or when help is printed because
+	// no runnable command was selected (default Failure).
+	Usage Code
+
+	// Runtime is returned for errors produced by a command (default Failure).
+	Runtime Code
 
-	// TODO: should this be used or removed?
-	// message string
+	// Help is returned after printing help requested by --help (default Success).
+	Help Code
+
+	// Crash is returned when a command panics (default Crash).
+	Crash Code
+}
+
+func (e *ExitCodes) code(res *result) Code {
+	if e == nil {
+		return res.code
+	}
+
+	var code Code
+	switch res.kind {
+	case usageKind:
+		code = e.Usage
+	case runtimeKind:
+		code = e.Runtime
+	case helpKind:
+		code = e.Help
+	case crashKind:
+		code = e.Crash
+	case otherKind:
+	}
+
+	if code == 0 {
+		return res.code
+	}
+	return code
 }
 
 type Configuration struct {
@@ -33,28 +93,243 @@ type Configuration struct {
 	Top       *Component
 	Globals   Flags
 	Version   string
-	Output    io.Writer
 	Context   context.Context
+
+	// VersionInfo describes the build of the program in more detail than
+	// Version, which it replaces. It adds the --version global flag, and a
+	// "version" command with a --json flag if Top has subcommands. See
+	// BuildVersion.
+	VersionInfo *VersionInfo
+
+	// Updates enables checking for a newer version of the program after a
+	// command completes.
+	Updates *Updates
+
+	// Reporter receives the command path, flags given, duration, and exit
+	// code of each run.
+	Reporter Reporter
+
+	// AuditLog receives a line of JSON for each run, with the time, command
+	// path, flag values with secrets redacted, and exit code.
+	AuditLog io.Writer
+
+	// Profile adds the hidden --profile global flag, which prints the time
+	// taken by parsing, each hook, and the Function of a command on exit.
+	Profile bool
+
+	// Pprof adds the hidden --cpuprofile, --memprofile, and --trace global
+	// flags, which write profiles of a command for go tool pprof and go tool
+	// trace.
+	Pprof bool
+
+	// CrashDir is a directory where a report is written when a command
+	// panics, in addition to Stderr.
+	CrashDir string
+
+	// Output is where both normal output and errors are written, unless
+	// replaced by Stdout or Stderr.
+	Output io.Writer
+
+	// Stdout receives the output of commands and requested help.
+	Stdout io.Writer
+
+	// Stderr receives errors and help printed on usage errors.
+	Stderr io.Writer
+
+	// WarnOutput receives warnings, such as for deprecated flags and
+	// commands, and those of Component.Warnf (default Stderr).
+	WarnOutput io.Writer
+
+	// Stdin is read for secrets and passed to plugins (default os.Stdin).
+	Stdin io.Reader
+
+	// Getenv looks up environment variables (default os.Getenv).
+	Getenv func(key string) string
+
+	// Plugins enables running an unknown subcommand as the executable
+	// "<Plugins>-<subcommand>" found in PATH, like git and kubectl.
+	Plugins string
+
+	// ErrorHandler is called with any parse or runtime error, and returns the
+	// exit code of Run. By default the error is written to Output.
+	ErrorHandler func(err error) Code
+
+	// ExitCodes maps the outcome of Run, such as a usage error or a panic, to
+	// the exit code returned for it. By default Run returns Failure for usage
+	// and runtime errors, Success after help, and Crash after a panic.
+	ExitCodes *ExitCodes
+
+	// Width overrides the detected terminal width used to wrap help text.
+	Width int
+
+	// Messages translates help headings and built-in messages.
+	Messages Catalog
+
+	// Pager enables piping help taller than the terminal through $PAGER.
+	Pager bool
+
+	// Sort is the order of commands and flags in help (default DeclarationOrder).
+	Sort SortOrder
+
+	// HelpLayout controls the columns of flags and commands in help.
+	HelpLayout HelpLayout
+
+	// GlobalsHelp is how global flags are shown in the help of subcommands
+	// (default GlobalsListed).
+	GlobalsHelp GlobalsHelp
+
+	// Completion adds a "completion" command for generating shell
+	// completion scripts, if Top has subcommands and none named "completion".
+	Completion bool
+
+	// Docs adds a "docs" command writing the Markdown pages and man page of
+	// the command tree into a directory, such as for publishing from CI.
+	Docs bool
+
+	// Signals cancel the context of the command when received.
+	Signals []os.Signal
+
+	// ShutdownTimeout is how long a command may keep running after one of
+	// the Signals is received (default 5 seconds). A command still running
+	// then has its cleanups run, given as long again, and the program exits
+	// with Failure.
+	ShutdownTimeout time.Duration
+
+	// Verbosity adds the --quiet and --verbose global flags, which set the
+	// level of the Logger of a command.
+	Verbosity bool
+
+	// Logger receives diagnostics about the parsing and running of commands.
+	Logger *slog.Logger
+
+	// Formats adds the --output global flag, selecting the format in which
+	// Emit renders values.
+	Formats bool
+
+	// Duplicates is how flags without Repeats given more than once are
+	// handled (default DuplicatesDeferred).
+	Duplicates Duplicates
+
+	// Prompt is shown by Shell before each command line (default the name of
+	// the program followed by "> ").
+	Prompt string
+
+	// LineReader reads the command lines of Shell, replacing the default
+	// line editor.
+	LineReader LineReader
+
+	// Aliases expand a subcommand name of Top into arguments, split like a
+	// shell would, the way git aliases work. Aliases may use other aliases,
+	// but never replace a subcommand of Top. See ReadAliases.
+	Aliases map[string]string
+
+	// ArgumentsEnv names an environment variable, such as "MYTOOL_OPTS",
+	// whose value is split like a shell would and placed before Arguments,
+	// so flags of Top and Globals can be given sticky defaults.
+	ArgumentsEnv string
+
+	// OnUnknownCommand is called with the remaining arguments in place of
+	// failing when the arguments name a subcommand which is not defined, and
+	// which no alias, plugin, or Fallback handles. It returns the exit code.
+	OnUnknownCommand func(c *Component, name string, rest []string) Code
+
+	// Authorize reports whether the subcommand with path, the names from Top
+	// down, may be run, such as by the role of the authenticated user.
+	// Subcommands which may not are left out of help and fail when run.
+	Authorize func(path []string) bool
 }
 
 func Arguments() []string {
 	return os.Args[1:]
 }
 
+// New prepares a run of the component tree of c. The tree is not modified,
+// so New may be called again on c, for example with different Arguments, and
+// each Runnable starts from a clean state. Runs of the same tree may happen
+// concurrently, as long as c is not modified while New is called.
 func New(c *Configuration) *Runnable {
-	arguments := slices.Clone(c.Arguments)
-	slices.Reverse(arguments)
-	c.Top.args = stacks.Simple(arguments...)
-	c.Top.version = c.Version
-	c.Top.globals = c.globals()
-	c.Top.context = c.context()
-	output := c.Output
-	if output == nil {
-		output = os.Stderr
+	getenv := c.getenv()
+	arguments, err := c.arguments(getenv)
+
+	top := *c.Top
+	if c.Completion && !top.Leaf() && !top.Components.Contains("completion") {
+		top.Components = append(slices.Clip(top.Components), newCompletionComponent())
 	}
+	if c.Docs && !top.Leaf() && !top.Components.Contains("docs") {
+		top.Components = append(slices.Clip(top.Components), newDocsComponent())
+	}
+	if c.VersionInfo != nil && !top.Leaf() && !top.Components.Contains("version") {
+		top.Components = append(slices.Clip(top.Components), newVersionComponent())
+	}
+
+	stdout := c.stdout()
+	top.state = &state{
+		args:      cursor{args: arguments},
+		err:       err,
+		vals:      reclaim(c.Top),
+		globals:   c.globals(),
+		dups:      c.Duplicates,
+		version:   c.version(),
+		plugins:   c.Plugins,
+		aliases:   c.Aliases,
+		expanded:  make(map[string]bool),
+		authorize: c.Authorize,
+		onUnknown: c.OnUnknownCommand,
+		style:     c.style(stdout, getenv),
+		cleanups:  new(cleanups),
+		profile:   c.profile(),
+		stdin:     c.stdin(),
+		stdout:    stdout,
+		stderr:    c.stderr(),
+		warn:      c.warnOutput(),
+		getenv:    getenv,
+		logger:    c.logger(),
+		context:   c.context(),
+	}
+
 	return &Runnable{
-		root:   c.Top,
-		output: output,
+		tree:     c.Top,
+		root:     &top,
+		output:   top.stderr,
+		handler:  c.ErrorHandler,
+		codes:    c.ExitCodes,
+		signals:  c.Signals,
+		shutdown: c.shutdownTimeout(),
+		exit:     os.Exit,
+		updates:  c.Updates,
+		reporter: c.Reporter,
+		auditLog: c.AuditLog,
+		crashDir: c.CrashDir,
+	}
+}
+
+// arguments returns the arguments of c, after those from ArgumentsEnv.
+func (c *Configuration) arguments(getenv func(string) string) ([]string, error) {
+	if c.ArgumentsEnv == "" {
+		return slices.Clone(c.Arguments), nil
+	}
+	options, err := fields(getenv(c.ArgumentsEnv))
+	if err != nil {
+		return slices.Clone(c.Arguments), parsef(ErrBadValue, "unable to split %s: %v", c.ArgumentsEnv, err)
+	}
+	return append(options, c.Arguments...), nil
+}
+
+func (c *Configuration) profile() *profile {
+	if !c.Profile {
+		return nil
+	}
+	return new(profile)
+}
+
+func (c *Configuration) version() *VersionInfo {
+	switch {
+	case c.VersionInfo != nil:
+		return c.VersionInfo
+	case c.Version != "":
+		return &VersionInfo{Version: c.Version}
+	default:
+		return nil
 	}
 }
 
@@ -65,26 +340,286 @@ func (c *Configuration) context() context.Context {
 	return c.Context
 }
 
+func (c *Configuration) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.New(discard{})
+	}
+	return c.Logger
+}
+
+func (c *Configuration) stdin() io.Reader {
+	if c.Stdin == nil {
+		return os.Stdin
+	}
+	return c.Stdin
+}
+
+const defaultShutdownTimeout = 5 * time.Second
+
+func (c *Configuration) shutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return c.ShutdownTimeout
+}
+
+func (c *Configuration) getenv() func(string) string {
+	if c.Getenv == nil {
+		return os.Getenv
+	}
+	return c.Getenv
+}
+
+func (c *Configuration) stdout() io.Writer {
+	switch {
+	case c.Stdout != nil:
+		return c.Stdout
+	case c.Output != nil:
+		return c.Output
+	default:
+		return os.Stdout
+	}
+}
+
+func (c *Configuration) stderr() io.Writer {
+	switch {
+	case c.Stderr != nil:
+		return c.Stderr
+	case c.Output != nil:
+		return c.Output
+	default:
+		return os.Stderr
+	}
+}
+
+func (c *Configuration) warnOutput() io.Writer {
+	if c.WarnOutput != nil {
+		return c.WarnOutput
+	}
+	return c.stderr()
+}
+
+func (c *Configuration) style(output io.Writer, getenv func(string) string) *style {
+	width := c.Width
+	if width == 0 {
+		width = terminalWidth(output, getenv)
+	}
+	return &style{
+		width:    width,
+		messages: c.Messages,
+		pager:    c.Pager,
+		order:    c.Sort,
+		globals:  c.GlobalsHelp,
+		layout:   c.HelpLayout,
+	}
+}
+
 func (c *Configuration) globals() Flags {
-	return append(c.Globals, helpFlag)
+	globals := slices.Clone(c.Globals)
+	if c.Verbosity {
+		globals = append(globals, quietFlag, verboseFlag)
+	}
+	if c.Formats {
+		globals = append(globals, outputFlag)
+	}
+	if c.VersionInfo != nil {
+		globals = append(globals, versionFlag)
+	}
+	if c.Profile {
+		globals = append(globals, profileFlag)
+	}
+	if c.Pprof {
+		globals = append(globals, cpuProfileFlag, memProfileFlag, traceFlag)
+	}
+	return append(globals, helpFlag, helpAllFlag)
 }
 
 type Runnable struct {
-	root   *Component
-	output io.Writer
+	tree     *Component
+	root     *Component
+	output   io.Writer
+	handler  func(error) Code
+	codes    *ExitCodes
+	signals  []os.Signal
+	shutdown time.Duration
+	exit     func(Code)
+	updates  *Updates
+	reporter Reporter
+	auditLog io.Writer
+	crashDir string
+
+	parsed bool
+	leaf   *Component
+	err    error
 }
 
-func (r *Runnable) Run() (c Code) {
+// Parse resolves the flags and subcommands of the arguments without running
+// any command. It returns the component the arguments resolve to, whose flag
+// values and arguments can be inspected, or a *ParseError if the arguments
+// are not valid.
+func (r *Runnable) Parse() (*Component, error) {
+	if !r.parsed {
+		r.leaf, r.err = r.root.parse()
+		r.parsed = true
+	}
+	return r.leaf, r.err
+}
+
+// Parse resolves the arguments of config like Runnable.Parse, for untrusted
+// input. It never runs a command, writes to a stream, looks up plugins, or
+// panics; a panic while parsing is returned as an error.
+func Parse(config *Configuration) (*Component, error) {
+	return parseOnly(config, false)
+}
+
+// parseOnly parses the arguments of config for Parse, or for VerifyExamples if
+// example is set, in which case the files and directories named by flags are
+// not checked.
+func parseOnly(config *Configuration, example bool) (leaf *Component, err error) {
 	defer func() {
 		if p := recover(); p != nil {
-			_, _ = io.WriteString(r.output, p.(string))
-			c = Failure
+			leaf = nil
+			if err = misuse(p); err == nil {
+				err = fmt.Errorf("babycli: %v", p)
+			}
 		}
 	}()
+
+	c := *config
+	c.Output = io.Discard
+	c.Stdout = io.Discard
+	c.Stderr = io.Discard
+	c.WarnOutput = io.Discard
+	c.Stdin = strings.NewReader("")
+	c.Plugins = ""
+	c.Pager = false
+	r := New(&c)
+	r.root.example = example
+	return r.Parse()
+}
+
+// Run parses the arguments and runs the command they resolve to. The flag
+// values of the run are reused once the next run of the same Top starts, so
+// the components of a run, including the one Parse returns, must not be used
+// after that.
+func (r *Runnable) Run() (c Code) {
+	start := time.Now()
+	defer func() {
+		r.report(start, c)
+		r.audit(start, c)
+		r.root.vals.retire(r.tree)
+	}()
+
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		if err := misuse(p); err != nil {
+			if r.handler != nil {
+				c = r.handler(err)
+				return
+			}
+			_, _ = io.WriteString(r.output, err.Error())
+			c = r.codes.code(&result{code: Failure, kind: runtimeKind})
+			return
+		}
+
+		err := &PanicError{Value: p, Stack: debug.Stack()}
+		if r.handler != nil {
+			c = r.handler(err)
+			return
+		}
+		r.crash(err)
+		c = r.codes.code(&result{code: Crash, kind: crashKind})
+	}()
+
+	returned := make(chan struct{})
+	if len(r.signals) > 0 {
+		ctx, stop := signal.NotifyContext(r.root.context, r.signals...)
+		defer stop()
+		go r.interrupt(r.root.context, ctx, returned)
+		r.root.context = ctx
+	}
+
+	defer r.root.cleanups.run()
+	defer close(returned)
+
 	result := r.run()
+	r.printProfile(start)
+	r.checkUpdates()
 	return result.code
 }
 
+// interrupt waits for one of the signals to cancel ctx, and then for the
+// command to return. If it has not returned by the shutdown timeout, its
+// cleanups are run, for at most as long again, and the program exits.
+func (r *Runnable) interrupt(parent, ctx context.Context, returned <-chan struct{}) {
+	select {
+	case <-returned:
+		return
+	case <-ctx.Done():
+	}
+	if parent.Err() != nil {
+		return
+	}
+
+	timer := time.NewTimer(r.shutdown)
+	defer timer.Stop()
+	select {
+	case <-returned:
+		return
+	case <-timer.C:
+	}
+
+	r.root.logger.Debug("babycli: command did not stop after signal", "timeout", r.shutdown)
+	done := make(chan struct{})
+	go func() {
+		r.root.cleanups.run()
+		close(done)
+	}()
+	timer.Reset(r.shutdown)
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+	r.exit(r.codes.code(&result{code: Failure, kind: runtimeKind}))
+}
+
 func (r *Runnable) run() *result {
-	return r.root.run(r.output)
+	start := time.Now()
+	_, err := r.Parse()
+	r.root.measure("parse", start)
+	if err != nil {
+		return r.fail(&result{code: Failure, err: err, kind: usageKind})
+	}
+	return r.fail(r.leaf.run())
+}
+
+// fail reports the error of res, if any, through the error handler.
+func (r *Runnable) fail(res *result) *result {
+	res.code = r.codes.code(res)
+
+	if res.err != nil {
+		r.root.logger.Debug("babycli: command failed", "error", res.err, "code", res.code)
+	}
+
+	switch {
+	case res.err == nil:
+		return res
+	case r.handler != nil:
+		return &result{code: r.handler(res.err), err: res.err}
+	default:
+		var perr *ParseError
+		if !errors.As(res.err, &perr) {
+			write(r.output, res.err.Error())
+			return res
+		}
+		write(r.output, perr.localize(r.root.style))
+		if perr.component != nil {
+			write(r.output, perr.component.hint())
+		}
+		return res
+	}
 }
@@ -0,0 +1,218 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// LineReader reads the command lines of a Shell.
+type LineReader interface {
+	// ReadLine returns the next line entered after prompt, without the line
+	// ending, or io.EOF at the end of input.
+	ReadLine(prompt string) (string, error)
+}
+
+const exitCommand = "exit"
+
+// Shell runs config as an interactive shell. Each line read is split into
+// arguments the way a POSIX shell would, and run like Run with those
+// Arguments. The shell ends at the end of input or on "exit", returning the
+// exit code of the last command.
+//
+// Lines are read with the LineReader of config. By default a terminal gets a
+// line editor with history, reverse search with ^R, and completion with tab,
+// while other input is read one line at a time.
+func Shell(config *Configuration) Code {
+	reader := config.LineReader
+	if reader == nil {
+		reader = config.lineReader()
+	}
+
+	prompt := config.Prompt
+	if prompt == "" {
+		prompt = program(config.Top) + "> "
+	}
+
+	code := Success
+	for {
+		line, err := reader.ReadLine(prompt)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				writef(config.stderr(), "babycli: unable to read command: %v", err)
+				return Failure
+			}
+			return code
+		}
+
+		args, err := fields(line)
+		switch {
+		case err != nil:
+			writef(config.stderr(), "babycli: %v", err)
+			code = Failure
+			continue
+		case len(args) == 0:
+			continue
+		case len(args) == 1 && args[0] == exitCommand && !config.Top.Components.Contains(exitCommand):
+			return code
+		}
+
+		c := *config
+		c.Arguments = args
+		code = New(&c).Run()
+	}
+}
+
+// lineReader returns the default LineReader for the input of c.
+func (c *Configuration) lineReader() LineReader {
+	in := c.stdin()
+	if f, ok := in.(*os.File); ok {
+		if _, _, ok := terminalSize(f); ok {
+			return newEditor(f, c.stdout(), c.complete)
+		}
+	}
+	return &plainReader{in: unbuffered{in}}
+}
+
+// complete returns the completions of the last word of line for the
+// component tree of c.
+func (c *Configuration) complete(line string) []string {
+	words, err := fields(line)
+	if err != nil {
+		return nil
+	}
+
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	run := *c
+	run.Arguments = nil
+	run.Stdin = strings.NewReader("")
+	run.Stdout = io.Discard
+	run.Stderr = io.Discard
+	return New(&run).root.candidates(words, partial)
+}
+
+// plainReader reads lines without editing or prompting, for input which is
+// not a terminal.
+type plainReader struct {
+	in unbuffered
+}
+
+func (r *plainReader) ReadLine(string) (string, error) {
+	return r.in.readLine()
+}
+
+// unbuffered reads from r one byte at a time, never reading ahead, so the
+// commands run by a Shell can read the same input after it.
+type unbuffered struct {
+	r io.Reader
+}
+
+func (u unbuffered) ReadByte() (byte, error) {
+	var b [1]byte
+	for {
+		n, err := u.r.Read(b[:])
+		if n == 1 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (u unbuffered) ReadRune() (rune, int, error) {
+	var b [utf8.UTFMax]byte
+	n := 0
+	for n == 0 || !utf8.FullRune(b[:n]) {
+		c, err := u.ReadByte()
+		if err != nil {
+			return 0, 0, err
+		}
+		b[n] = c
+		n++
+	}
+	r, size := utf8.DecodeRune(b[:n])
+	return r, size, nil
+}
+
+// readLine reads through the next newline, and returns the line without its
+// ending, or io.EOF if nothing is left to read.
+func (u unbuffered) readLine() (string, error) {
+	var line []byte
+	for {
+		b, err := u.ReadByte()
+		switch {
+		case err == io.EOF && len(line) > 0, err == nil && b == '\n':
+			return strings.TrimSuffix(string(line), "\r"), nil
+		case err != nil:
+			return "", err
+		}
+		line = append(line, b)
+	}
+}
+
+// fields splits line into words at unquoted whitespace. Single quotes
+// preserve every character, while in double quotes and outside of quotes a
+// backslash escapes the next character.
+func fields(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	switch {
+	case quote != 0:
+		return nil, errors.New("unterminated quote")
+	case escaped:
+		return nil, errors.New("unterminated escape")
+	case inWord:
+		words = append(words, word.String())
+	}
+	return words, nil
+}
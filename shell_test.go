@@ -0,0 +1,294 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+type scriptedLines []string
+
+func (ls *scriptedLines) ReadLine(string) (string, error) {
+	if len(*ls) == 0 {
+		return "", io.EOF
+	}
+	line := (*ls)[0]
+	*ls = (*ls)[1:]
+	return line, nil
+}
+
+func shellConfig(out io.Writer, reader LineReader) *Configuration {
+	return &Configuration{
+		Output:     out,
+		LineReader: reader,
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "say",
+					Flags: Flags{
+						{Type: StringFlag, Long: "color", Choices: []string{"red", "green"}},
+						{Type: BooleanFlag, Long: "loud", Short: "l"},
+					},
+					Function: func(c *Component) Code {
+						words := c.Arguments()
+						if c.GetBool("loud") {
+							words = append(words, "!")
+						}
+						writef(c.Stdout(), "%s", strings.Join(words, " "))
+						return Success
+					},
+				},
+				{
+					Name: "fail",
+					Function: func(*Component) Code {
+						return Failure
+					},
+				},
+				{
+					Name: "sum",
+				},
+			},
+		},
+	}
+}
+
+func TestShell(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		lines   scriptedLines
+		expCode Code
+		expOut  string
+	}{
+		{
+			name:    "commands",
+			lines:   scriptedLines{"say hello", "", "say -l 'hello world'"},
+			expCode: Success,
+			expOut:  "hello\nhello world !\n",
+		},
+		{
+			name:    "last code",
+			lines:   scriptedLines{"say hi", "fail"},
+			expCode: Failure,
+			expOut:  "hi\n",
+		},
+		{
+			name:    "exit",
+			lines:   scriptedLines{"say one", "exit", "say two"},
+			expCode: Success,
+			expOut:  "one\n",
+		},
+		{
+			name:    "unterminated quote",
+			lines:   scriptedLines{`say "hello`},
+			expCode: Failure,
+			expOut:  "babycli: unterminated quote\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := new(strings.Builder)
+			code := Shell(shellConfig(out, &tc.lines))
+			must.Eq(t, tc.expCode, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestShell_plain(t *testing.T) {
+	t.Parallel()
+
+	out := new(strings.Builder)
+	config := shellConfig(out, nil)
+	config.Stdin = strings.NewReader("say one\nsay two\n")
+	code := Shell(config)
+	must.Eq(t, Success, code)
+	must.Eq(t, "one\ntwo\n", out.String())
+}
+
+func TestShell_stdin(t *testing.T) {
+	t.Parallel()
+
+	out := new(strings.Builder)
+	config := shellConfig(out, nil)
+	config.Stdin = strings.NewReader("read\nhello\nsay done\n")
+	config.Top.Components = append(config.Top.Components, &Component{
+		Name: "read",
+		FunctionE: func(c *Component) error {
+			b := make([]byte, len("hello\n"))
+			if _, err := io.ReadFull(c.Stdin(), b); err != nil {
+				return err
+			}
+			write(c.Stdout(), strings.TrimSpace(string(b)))
+			return nil
+		},
+	})
+	code := Shell(config)
+	must.Eq(t, Success, code)
+	must.Eq(t, "hello\ndone\n", out.String())
+}
+
+func Test_commonPrefix(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		words []string
+		exp   string
+	}{
+		{words: []string{"say", "sum"}, exp: "s"},
+		{words: []string{"--color", "--colour"}, exp: "--colo"},
+		{words: []string{"héllo", "hélp"}, exp: "hél"},
+		{words: []string{"é", "è"}, exp: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.words, " "), func(t *testing.T) {
+			t.Parallel()
+			must.Eq(t, tc.exp, commonPrefix(tc.words))
+		})
+	}
+}
+
+func Test_fields(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		line   string
+		exp    []string
+		expErr string
+	}{
+		{line: "", exp: nil},
+		{line: "  a  b\tc ", exp: []string{"a", "b", "c"}},
+		{line: `a 'b c' "d e"`, exp: []string{"a", "b c", "d e"}},
+		{line: `a\ b 'c\d' "e\"f"`, exp: []string{"a b", `c\d`, `e"f`}},
+		{line: `a "" ''`, exp: []string{"a", "", ""}},
+		{line: `--name="a b"`, exp: []string{"--name=a b"}},
+		{line: `a 'b`, expErr: "unterminated quote"},
+		{line: `a\`, expErr: "unterminated escape"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			t.Parallel()
+
+			words, err := fields(tc.line)
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.exp, words)
+		})
+	}
+}
+
+func TestConfiguration_complete(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		line string
+		exp  []string
+	}{
+		{line: "", exp: []string{"say", "fail", "sum", "help"}},
+		{line: "s", exp: []string{"say", "sum"}},
+		{line: "say -", exp: []string{"--color", "--loud", "-l", "--help", "-h"}},
+		{line: "say --l", exp: []string{"--loud"}},
+		{line: "say --color ", exp: []string{"red", "green"}},
+		{line: "say --color g", exp: []string{"green"}},
+		{line: "say -- -", exp: nil},
+		{line: "x", exp: nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			t.Parallel()
+
+			config := shellConfig(io.Discard, nil)
+			must.Eq(t, tc.exp, config.complete(tc.line))
+		})
+	}
+}
+
+func TestEditor_ReadLine(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		input   string
+		history []string
+		exp     []string
+	}{
+		{
+			name:  "lines",
+			input: "one\rtwo\r",
+			exp:   []string{"one", "two"},
+		},
+		{
+			name:  "editing",
+			input: "helo\x1b[Dl\x01say \x05!\x7f\r",
+			exp:   []string{"say hello"},
+		},
+		{
+			name:  "kill",
+			input: "say hello world\x17there\x01\x0bagain\r",
+			exp:   []string{"again"},
+		},
+		{
+			name:    "history",
+			input:   "\x1b[A\x1b[A\r\x10\r",
+			history: []string{"first", "second"},
+			exp:     []string{"first", "first"},
+		},
+		{
+			name:    "search",
+			input:   "\x12fi\r\x12sec\x05 more\r",
+			history: []string{"first", "second", "third"},
+			exp:     []string{"first", "second more"},
+		},
+		{
+			name:  "complete",
+			input: "sa\t--co\tr\t\r",
+			exp:   []string{"say --color red "},
+		},
+		{
+			name:  "interrupt",
+			input: "say\x03ok\r",
+			exp:   []string{"", "ok"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := &editor{
+				in:       bufio.NewReader(strings.NewReader(tc.input + "\x04")),
+				out:      io.Discard,
+				history:  tc.history,
+				complete: shellConfig(io.Discard, nil).complete,
+			}
+
+			var result []string
+			for {
+				line, err := e.ReadLine("> ")
+				if err == io.EOF {
+					break
+				}
+				must.NoError(t, err)
+				result = append(result, line)
+			}
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var aliasName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// GenShellAliases writes a shell alias for the ShellAlias of top and of every
+// command below it, such as `alias mtd='mytool deploy'`, for the shell named
+// "bash", "zsh", or "fish".
+func GenShellAliases(w io.Writer, shell string, top *Component) error {
+	switch shell {
+	case "bash", "zsh", "fish":
+	default:
+		return fmt.Errorf("babycli: shell aliases for %q are not supported", shell)
+	}
+
+	bw := bufio.NewWriter(w)
+	name := program(top)
+
+	var err error
+	top.Walk(func(path []string, c *Component) bool {
+		switch {
+		case err != nil:
+			return false
+		case c.ShellAlias == "":
+			return true
+		}
+		if !aliasName.MatchString(c.ShellAlias) {
+			err = fmt.Errorf("babycli: shell alias %q is not valid", c.ShellAlias)
+			return false
+		}
+
+		if top.Name != "" {
+			path = path[1:]
+		}
+		command := strings.Join(append([]string{name}, path...), " ")
+
+		switch shell {
+		case "fish":
+			writef(bw, "function %s --wraps '%s'", c.ShellAlias, fishQuote(command))
+			writef(bw, "    %s $argv", command)
+			write(bw, "end")
+		default:
+			writef(bw, "alias %s='%s'", c.ShellAlias, strings.ReplaceAll(command, "'", `'\''`))
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestGenShellAliases(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "mytool",
+		Components: Components{
+			{
+				Name:       "deploy",
+				ShellAlias: "mtd",
+				Components: Components{
+					{Name: "rollback", ShellAlias: "mtr"},
+					{Name: "status"},
+				},
+			},
+			{Name: "logs", ShellAlias: "mtl"},
+		},
+	}
+
+	cases := []struct {
+		shell  string
+		exp    string
+		expErr string
+	}{
+		{
+			shell: "bash",
+			exp:   "alias mtd='mytool deploy'\nalias mtr='mytool deploy rollback'\nalias mtl='mytool logs'\n",
+		},
+		{
+			shell: "fish",
+			exp: `function mtd --wraps 'mytool deploy'
+    mytool deploy $argv
+end
+function mtr --wraps 'mytool deploy rollback'
+    mytool deploy rollback $argv
+end
+function mtl --wraps 'mytool logs'
+    mytool logs $argv
+end
+`,
+		},
+		{
+			shell:  "powershell",
+			expErr: `babycli: shell aliases for "powershell" are not supported`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			w := new(strings.Builder)
+			err := GenShellAliases(w, tc.shell, top)
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.exp, w.String())
+		})
+	}
+}
+
+func TestGenShellAliases_invalid(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name:       "mytool",
+		Components: Components{{Name: "deploy", ShellAlias: "m d"}},
+	}
+
+	err := GenShellAliases(new(strings.Builder), "zsh", top)
+	must.EqError(t, err, `babycli: shell alias "m d" is not valid`)
+}
@@ -0,0 +1,415 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Spec is the serializable description of a component and its descendants.
+// Besides the JSON written by WriteSpec and read by Load, its fields carry yaml
+// tags for the YAML read by the separate module noxide.lol/go/babycli/babyyaml.
+type Spec struct {
+	Name                 string        `json:"name"                             yaml:"name"`
+	Version              string        `json:"version,omitempty"                yaml:"version,omitempty"`
+	Help                 string        `json:"help,omitempty"                   yaml:"help,omitempty"`
+	Description          string        `json:"description,omitempty"            yaml:"description,omitempty"`
+	Examples             []ExampleSpec `json:"examples,omitempty"               yaml:"examples,omitempty"`
+	Category             string        `json:"category,omitempty"               yaml:"category,omitempty"`
+	Hidden               bool          `json:"hidden,omitempty"                 yaml:"hidden,omitempty"`
+	ShellAlias           string        `json:"shell_alias,omitempty"            yaml:"shell_alias,omitempty"`
+	Deprecated           string        `json:"deprecated,omitempty"             yaml:"deprecated,omitempty"`
+	Unavailable          string        `json:"unavailable,omitempty"            yaml:"unavailable,omitempty"`
+	Default              string        `json:"default,omitempty"                yaml:"default,omitempty"`
+	PassThroughUnknown   bool          `json:"pass_through_unknown,omitempty"   yaml:"pass_through_unknown,omitempty"`
+	RunWithoutSubcommand bool          `json:"run_without_subcommand,omitempty" yaml:"run_without_subcommand,omitempty"`
+	Globals              []FlagSpec    `json:"globals,omitempty"                yaml:"globals,omitempty"`
+	Flags                []FlagSpec    `json:"flags,omitempty"                  yaml:"flags,omitempty"`
+	Persistent           []FlagSpec    `json:"persistent,omitempty"             yaml:"persistent,omitempty"`
+	Commands             []*Spec       `json:"commands,omitempty"               yaml:"commands,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// ExampleSpec is the serializable description of an example.
+type ExampleSpec struct {
+	Help    string `json:"help,omitempty" yaml:"help,omitempty"`
+	Command string `json:"command"        yaml:"command"`
+}
+
+// FlagSpec is the serializable description of a flag. The value of a default
+// hidden from help is left out, and only HideDefault is set. A default computed
+// by a Func is not evaluated, and only ComputedDefault is set.
+type FlagSpec struct {
+	Long            string   `json:"long,omitempty"              yaml:"long,omitempty"`
+	Short           string   `json:"short,omitempty"             yaml:"short,omitempty"`
+	Type            string   `json:"type"                        yaml:"type"`
+	Help            string   `json:"help,omitempty"              yaml:"help,omitempty"`
+	Require         bool     `json:"require,omitempty"           yaml:"require,omitempty"`
+	Repeats         bool     `json:"repeats,omitempty"           yaml:"repeats,omitempty"`
+	Default         any      `json:"default,omitempty"           yaml:"default,omitempty"`
+	Show            bool     `json:"show,omitempty"              yaml:"show,omitempty"`
+	HideDefault     bool     `json:"hide_default,omitempty"      yaml:"hide_default,omitempty"`
+	ComputedDefault bool     `json:"computed_default,omitempty"  yaml:"computed_default,omitempty"`
+	Choices         []string `json:"choices,omitempty"           yaml:"choices,omitempty"`
+	Placeholder     string   `json:"placeholder,omitempty"       yaml:"placeholder,omitempty"`
+	Min             int      `json:"min,omitempty"               yaml:"min,omitempty"`
+	Max             int      `json:"max,omitempty"               yaml:"max,omitempty"`
+	Hidden          bool     `json:"hidden,omitempty"            yaml:"hidden,omitempty"`
+	Deprecated      string   `json:"deprecated,omitempty"        yaml:"deprecated,omitempty"`
+	Group           string   `json:"group,omitempty"             yaml:"group,omitempty"`
+	Verbosity       bool     `json:"verbosity,omitempty"         yaml:"verbosity,omitempty"`
+	Separator       string   `json:"separator,omitempty"         yaml:"separator,omitempty"`
+	Layout          string   `json:"layout,omitempty"            yaml:"layout,omitempty"`
+	Encoding        string   `json:"encoding,omitempty"          yaml:"encoding,omitempty"`
+	CreateIfMissing bool     `json:"create_if_missing,omitempty" yaml:"create_if_missing,omitempty"`
+	Perm            string   `json:"perm,omitempty"              yaml:"perm,omitempty"`
+	Strict          bool     `json:"strict,omitempty"            yaml:"strict,omitempty"`
+	ExpandEnv       bool     `json:"expand_env,omitempty"        yaml:"expand_env,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Spec describes c and its descendants.
+func (c *Component) Spec() *Spec {
+	s := &Spec{
+		Name:                 c.Name,
+		Help:                 c.Help,
+		Description:          c.Description,
+		Category:             c.Category,
+		Hidden:               c.Hidden,
+		ShellAlias:           c.ShellAlias,
+		Deprecated:           c.Deprecated,
+		Unavailable:          c.Unavailable,
+		Default:              c.Default,
+		PassThroughUnknown:   c.PassThroughUnknown,
+		RunWithoutSubcommand: c.RunWithoutSubcommand,
+		Flags:                c.Flags.specs(),
+		Persistent:           c.Persistent.specs(),
+		Annotations:          maps.Clone(c.Annotations),
+	}
+	for _, example := range c.Examples {
+		s.Examples = append(s.Examples, ExampleSpec(example))
+	}
+	for _, cmd := range c.Components {
+		s.Commands = append(s.Commands, cmd.Spec())
+	}
+	return s
+}
+
+// WriteSpec writes the JSON description of the command tree of r, including
+// its global flags and version.
+func (r *Runnable) WriteSpec(w io.Writer) error {
+	s := r.root.Spec()
+	if r.root.version != nil {
+		s.Version = r.root.version.Version
+	}
+	s.Globals = r.root.globals.specs()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+func (fs Flags) specs() []FlagSpec {
+	specs := make([]FlagSpec, 0, len(fs))
+	for _, f := range fs {
+		specs = append(specs, f.spec())
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	return specs
+}
+
+func (f *Flag) spec() FlagSpec {
+	s := FlagSpec{
+		Long:            f.Long,
+		Short:           f.Short,
+		Type:            f.Type.String(),
+		Help:            f.Help,
+		Require:         f.Require,
+		Repeats:         f.Repeats,
+		Choices:         f.Choices,
+		Placeholder:     f.Placeholder,
+		Min:             f.MinOccurrences,
+		Max:             f.MaxOccurrences,
+		Hidden:          f.Hidden,
+		Deprecated:      f.Deprecated,
+		Group:           f.Group,
+		Verbosity:       f.Verbosity,
+		Separator:       f.Separator,
+		Layout:          f.Layout,
+		CreateIfMissing: f.CreateIfMissing,
+		Strict:          f.Strict,
+		ExpandEnv:       f.ExpandEnv,
+		Annotations:     maps.Clone(f.Annotations),
+	}
+	if f.Encoding != Base64 {
+		s.Encoding = f.Encoding.String()
+	}
+	if f.Perm != 0 {
+		s.Perm = fmt.Sprintf("%#o", f.Perm)
+	}
+	if f.Default != nil && f.Default.Hidden {
+		s.Show = f.Default.Show
+		s.HideDefault = true
+	}
+	if f.Default != nil && f.Default.Func != nil {
+		s.Show = f.Default.Show
+		s.ComputedDefault = true
+	}
+	if f.Default != nil && !f.Default.Hidden && f.Default.Func == nil && f.Type != SecretFlag {
+		s.Default = f.Default.Value
+		s.Show = f.Default.Show
+		switch d := s.Default.(type) {
+		case time.Duration:
+			s.Default = d.String()
+		case time.Time:
+			s.Default = d.Format(time.DateOnly)
+		case *big.Int:
+			s.Default = d.String()
+		case []byte:
+			s.Default = base64.StdEncoding.EncodeToString(d)
+		}
+	}
+	return s
+}
+
+// Load reads a JSON Spec, as written by WriteSpec, and returns a Configuration
+// with its component tree, version, and global flags. Functions are not part of
+// a Spec; attach them to the components found with Find before calling New,
+// along with the values of hidden defaults and the Func of computed ones. YAML specs are read by the Load
+// of the separate module noxide.lol/go/babycli/babyyaml.
+func Load(r io.Reader) (*Configuration, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	s := new(Spec)
+	if err := decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("babycli: unable to decode spec: %w", err)
+	}
+	return s.Configuration()
+}
+
+// Configuration converts s into a Configuration. Built-in global flags listed
+// in s, such as --help, are skipped as New adds them again.
+func (s *Spec) Configuration() (*Configuration, error) {
+	top, err := s.component()
+	if err != nil {
+		return nil, err
+	}
+
+	var globals Flags
+	for _, g := range s.Globals {
+		if builtin(g.Long) {
+			continue
+		}
+		f, err := g.flag()
+		if err != nil {
+			return nil, err
+		}
+		globals = append(globals, f)
+	}
+
+	return &Configuration{
+		Top:     top,
+		Globals: globals,
+		Version: s.Version,
+	}, nil
+}
+
+func builtin(long string) bool {
+	for _, f := range []*Flag{helpFlag, helpAllFlag, quietFlag, verboseFlag, outputFlag, versionFlag, profileFlag, cpuProfileFlag, memProfileFlag, traceFlag} {
+		if f.Long == long {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Spec) component() (*Component, error) {
+	c := &Component{
+		Name:                 s.Name,
+		Help:                 s.Help,
+		Description:          s.Description,
+		Category:             s.Category,
+		Hidden:               s.Hidden,
+		ShellAlias:           s.ShellAlias,
+		Deprecated:           s.Deprecated,
+		Unavailable:          s.Unavailable,
+		Default:              s.Default,
+		PassThroughUnknown:   s.PassThroughUnknown,
+		RunWithoutSubcommand: s.RunWithoutSubcommand,
+		Annotations:          maps.Clone(s.Annotations),
+	}
+	for _, example := range s.Examples {
+		c.Examples = append(c.Examples, Example(example))
+	}
+	var err error
+	if c.Flags, err = flagsOf(s.Flags); err != nil {
+		return nil, err
+	}
+	if c.Persistent, err = flagsOf(s.Persistent); err != nil {
+		return nil, err
+	}
+	for _, cmd := range s.Commands {
+		child, err := cmd.component()
+		if err != nil {
+			return nil, err
+		}
+		c.Components = append(c.Components, child)
+	}
+	return c, nil
+}
+
+func flagsOf(specs []FlagSpec) (Flags, error) {
+	var flags Flags
+	for _, fs := range specs {
+		f, err := fs.flag()
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
+func (fs *FlagSpec) flag() (*Flag, error) {
+	f := &Flag{
+		Long:            fs.Long,
+		Short:           fs.Short,
+		Help:            fs.Help,
+		Require:         fs.Require,
+		Repeats:         fs.Repeats,
+		Choices:         fs.Choices,
+		Placeholder:     fs.Placeholder,
+		MinOccurrences:  fs.Min,
+		MaxOccurrences:  fs.Max,
+		Hidden:          fs.Hidden,
+		Deprecated:      fs.Deprecated,
+		Group:           fs.Group,
+		Verbosity:       fs.Verbosity,
+		Separator:       fs.Separator,
+		Layout:          fs.Layout,
+		CreateIfMissing: fs.CreateIfMissing,
+		Strict:          fs.Strict,
+		ExpandEnv:       fs.ExpandEnv,
+		Annotations:     maps.Clone(fs.Annotations),
+	}
+
+	switch fs.Type {
+	case "string":
+		f.Type = StringFlag
+	case "integer":
+		f.Type = IntFlag
+	case "boolean":
+		f.Type = BooleanFlag
+	case "duration":
+		f.Type = DurationFlag
+	case "secret":
+		f.Type = SecretFlag
+	case "date":
+		f.Type = DateFlag
+	case "big integer":
+		f.Type = BigIntFlag
+	case "bytes":
+		f.Type = BytesLiteralFlag
+	case "file":
+		f.Type = OpenFileFlag
+	case "directory":
+		f.Type = DirFlag
+	case "pattern":
+		f.Type = GlobFlag
+	default:
+		return nil, fmt.Errorf("babycli: flag %q has unknown type %q", f.Identity(), fs.Type)
+	}
+
+	switch fs.Encoding {
+	case "", "base64":
+		f.Encoding = Base64
+	case "base64url":
+		f.Encoding = Base64URL
+	case "hex":
+		f.Encoding = Hex
+	default:
+		return nil, fmt.Errorf("babycli: flag %q has unknown encoding %q", f.Identity(), fs.Encoding)
+	}
+
+	if fs.Perm != "" {
+		perm, err := strconv.ParseUint(fs.Perm, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("babycli: flag %q has invalid perm %q", f.Identity(), fs.Perm)
+		}
+		f.Perm = os.FileMode(perm)
+	}
+
+	if fs.ComputedDefault && fs.Default != nil {
+		return nil, fmt.Errorf("babycli: flag %q has both a default and a computed default", f.Identity())
+	}
+	if fs.Default == nil {
+		if fs.HideDefault || fs.ComputedDefault {
+			f.Default = &Default{Show: fs.Show, Hidden: fs.HideDefault}
+		}
+		return f, nil
+	}
+
+	value, ok := fs.defaultValue(f.Type)
+	if !ok {
+		return nil, fmt.Errorf("babycli: default %v of %s flag %q is not valid", fs.Default, f.Type, f.Identity())
+	}
+	f.Default = &Default{Value: value, Show: fs.Show, Hidden: fs.HideDefault}
+	return f, nil
+}
+
+// defaultValue converts the decoded default of fs into the Go type used for
+// flags of type t.
+func (fs *FlagSpec) defaultValue(t FlagType) (any, bool) {
+	switch v := fs.Default.(type) {
+	case string:
+		switch t {
+		case StringFlag, SecretFlag, OpenFileFlag, DirFlag, GlobFlag:
+			return v, true
+		case DurationFlag:
+			d, err := time.ParseDuration(v)
+			return d, err == nil
+		case DateFlag:
+			d, err := time.ParseInLocation(time.DateOnly, v, time.UTC)
+			return d, err == nil
+		case BigIntFlag:
+			return new(big.Int).SetString(v, 0)
+		case BytesLiteralFlag:
+			b, err := base64.StdEncoding.DecodeString(v)
+			return b, err == nil
+		case IntFlag, BooleanFlag:
+		}
+	case float64:
+		if t == IntFlag && v == math.Trunc(v) {
+			return int(v), true
+		}
+	case int:
+		if t == IntFlag {
+			return v, true
+		}
+	case bool:
+		if t == BooleanFlag {
+			return v, true
+		}
+	case time.Time:
+		// YAML decodes an unquoted date as a timestamp.
+		if t == DateFlag {
+			return v.UTC(), true
+		}
+	}
+	return nil, false
+}
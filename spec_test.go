@@ -0,0 +1,367 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRunnable_WriteSpec(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Version: "v1.2.3",
+		Globals: Flags{
+			{Type: DurationFlag, Long: "timeout", Default: &Default{Value: 2 * time.Minute}},
+		},
+		Top: &Component{
+			Name: "tool",
+			Help: "a useful tool",
+			Components: Components{
+				{
+					Name:        "deploy",
+					Category:    "management",
+					Annotations: map[string]string{"owner": "platform-team"},
+					Flags: Flags{
+						{Type: StringFlag, Long: "format", Short: "f", Require: true, Choices: []string{"json", "yaml"}},
+						{Type: SecretFlag, Long: "token", Default: &Default{Value: "hunter2"}},
+					},
+				},
+			},
+		},
+	}
+
+	w := new(strings.Builder)
+	must.NoError(t, New(config).WriteSpec(w))
+	must.Eq(t, `{
+  "name": "tool",
+  "version": "v1.2.3",
+  "help": "a useful tool",
+  "globals": [
+    {
+      "long": "timeout",
+      "type": "duration",
+      "default": "2m0s"
+    },
+    {
+      "long": "help",
+      "short": "h",
+      "type": "boolean",
+      "help": "print help message"
+    },
+    {
+      "long": "help-all",
+      "type": "boolean",
+      "help": "print every command below this one",
+      "hidden": true
+    }
+  ],
+  "commands": [
+    {
+      "name": "deploy",
+      "category": "management",
+      "flags": [
+        {
+          "long": "format",
+          "short": "f",
+          "type": "string",
+          "require": true,
+          "choices": [
+            "json",
+            "yaml"
+          ]
+        },
+        {
+          "long": "token",
+          "type": "secret"
+        }
+      ],
+      "annotations": {
+        "owner": "platform-team"
+      }
+    }
+  ]
+}
+`, w.String())
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	spec := `{
+  "name": "tool",
+  "version": "v1.2.3",
+  "globals": [
+    {"long": "timeout", "type": "duration", "default": "30s"},
+    {"long": "help", "short": "h", "type": "boolean"}
+  ],
+  "commands": [
+    {
+      "name": "deploy",
+      "flags": [
+        {"long": "replicas", "short": "r", "type": "integer", "default": 3, "show": true},
+        {"long": "format", "type": "string", "choices": ["json", "yaml"]}
+      ]
+    }
+  ]
+}`
+
+	config, err := Load(strings.NewReader(spec))
+	must.NoError(t, err)
+	must.Eq(t, "v1.2.3", config.Version)
+	must.Len(t, 1, config.Globals)
+
+	var replicas int
+	var timeout time.Duration
+	config.Top.Find("deploy").Function = func(c *Component) Code {
+		replicas = c.GetInt("replicas")
+		timeout = c.GetDuration("timeout")
+		return Success
+	}
+	config.Arguments = []string{"deploy", "-r", "5"}
+	config.Output = new(strings.Builder)
+
+	must.Zero(t, New(config).Run())
+	must.Eq(t, 5, replicas)
+	must.Eq(t, 30*time.Second, timeout)
+}
+
+func TestLoad_errors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		spec string
+		exp  string
+	}{
+		{
+			name: "unknown field",
+			spec: `{"name": "tool", "alias": "t"}`,
+			exp:  `babycli: unable to decode spec: json: unknown field "alias"`,
+		},
+		{
+			name: "unknown type",
+			spec: `{"name": "tool", "flags": [{"long": "size", "type": "float"}]}`,
+			exp:  `babycli: flag "size" has unknown type "float"`,
+		},
+		{
+			name: "bad default",
+			spec: `{"name": "tool", "flags": [{"long": "size", "type": "integer", "default": 1.5}]}`,
+			exp:  `babycli: default 1.5 of integer flag "size" is not valid`,
+		},
+		{
+			name: "computed default with value",
+			spec: `{"name": "tool", "flags": [{"long": "size", "type": "integer", "default": 1, "computed_default": true}]}`,
+			exp:  `babycli: flag "size" has both a default and a computed default`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Load(strings.NewReader(tc.spec))
+			must.EqError(t, err, tc.exp)
+		})
+	}
+}
+
+func TestRunnable_WriteSpec_roundtrip(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name:        "deploy",
+					Annotations: map[string]string{"docs/section": "operations", "release/since": "v1.4"},
+					Flags: Flags{
+						{Type: DurationFlag, Long: "wait", Default: &Default{Value: time.Minute, Show: true}},
+						{Type: StringFlag, Long: "manifest", Annotations: map[string]string{"file-completion": "true"}},
+					},
+				},
+			},
+		},
+	}
+
+	first := new(strings.Builder)
+	must.NoError(t, New(config).WriteSpec(first))
+
+	loaded, err := Load(strings.NewReader(first.String()))
+	must.NoError(t, err)
+
+	second := new(strings.Builder)
+	must.NoError(t, New(loaded).WriteSpec(second))
+	must.Eq(t, first.String(), second.String())
+	must.Eq(t, "v1.4", loaded.Top.Find("deploy").Annotations["release/since"])
+	must.Eq(t, "true", loaded.Top.Find("deploy").Flags.Get("manifest").Annotations["file-completion"])
+}
+
+func TestSpec_fields(t *testing.T) {
+	t.Parallel()
+
+	flag := &Flag{
+		Type:            BytesLiteralFlag,
+		Require:         true,
+		Repeats:         true,
+		Long:            "key",
+		Short:           "k",
+		Help:            "key to sign with",
+		Default:         &Default{Value: []byte{0x00, 0xff}, Show: true, Hidden: true},
+		Choices:         []string{"00ff", "ff00"},
+		Placeholder:     "HEX",
+		MinOccurrences:  1,
+		MaxOccurrences:  3,
+		Hidden:          true,
+		Deprecated:      "use --key-file",
+		Group:           "signing",
+		Verbosity:       true,
+		Transform:       func(value string) (string, error) { return value, nil },
+		Separator:       ",",
+		Layout:          time.DateOnly,
+		Encoding:        Hex,
+		CreateIfMissing: true,
+		Perm:            0o600,
+		Match:           func(string) ([]string, error) { return nil, nil },
+		Strict:          true,
+		ExpandEnv:       true,
+		Annotations:     map[string]string{"file-completion": "true"},
+	}
+	top := &Component{
+		Name:        "tool",
+		Help:        "a useful tool",
+		Description: "The tool does things.",
+		Examples:    []Example{{Help: "sign a file", Command: "tool sign -k 00ff notes.txt"}},
+		Category:    "tools",
+		Hidden:      true,
+		Annotations: map[string]string{"release/since": "v1.4"},
+		ShellAlias:  "tl",
+		Deprecated:  "use tool2",
+		Enabled:     func() bool { return true },
+		Unavailable: "not yet",
+		Components:  Components{{Name: "sign", Flags: Flags{{Type: BooleanFlag, Long: "detach"}}}},
+		Default:     "sign",
+		Function:    func(*Component) Code { return Success },
+		FunctionE:   func(*Component) error { return nil },
+		Before:      func(*Component) Code { return Success },
+		After:       func(*Component) Code { return Success },
+		Flags:       Flags{flag},
+		Persistent:  Flags{{Type: BooleanFlag, Long: "dry-run"}},
+
+		PassThroughUnknown:   true,
+		ValidateArgs:         func([]string) error { return nil },
+		RunWithoutSubcommand: true,
+		Fallback:             func(*Component) Code { return Success },
+		Resolve:              func(string) *Component { return nil },
+	}
+
+	// Every field is set, so a field added to Flag or Component fails here
+	// until it is set above, and then below unless a Spec carries it.
+	requireSet(t, "Flag", reflect.ValueOf(*flag))
+	requireSet(t, "Component", reflect.ValueOf(*top))
+
+	b, err := json.Marshal(top.Spec())
+	must.NoError(t, err)
+	loaded, err := Load(strings.NewReader(string(b)))
+	must.NoError(t, err)
+
+	// A hidden default keeps its value out of the Spec.
+	hidden := loaded.Top.Flags.Get("key").Default
+	must.Nil(t, hidden.Value)
+	hidden.Value = flag.Default.Value
+
+	requireSame(t, "Component", reflect.ValueOf(*top), reflect.ValueOf(*loaded.Top))
+}
+
+func TestSpec_computedDefault(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Flags: Flags{
+			{Type: IntFlag, Long: "workers", Default: &Default{Func: func() any { return 4 }, Show: true}},
+		},
+	}
+
+	b, err := json.Marshal(top.Spec())
+	must.NoError(t, err)
+	must.StrContains(t, string(b), `"computed_default":true`)
+	must.StrNotContains(t, string(b), `"default":`)
+
+	loaded, err := Load(strings.NewReader(string(b)))
+	must.NoError(t, err)
+
+	// A computed default keeps its Func out of the Spec.
+	computed := loaded.Top.Flags.Get("workers").Default
+	must.NotNil(t, computed)
+	must.Nil(t, computed.Value)
+	must.Nil(t, computed.Func)
+	must.True(t, computed.Show)
+	computed.Func = func() any { return 8 }
+
+	output := new(strings.Builder)
+	loaded.Output = output
+	loaded.Top.Function = func(c *Component) Code {
+		c.Printf("%d", c.GetInt("workers"))
+		return Success
+	}
+	must.Eq(t, Success, New(loaded).Run())
+	must.Eq(t, "8", output.String())
+}
+
+// spec returns whether field is one a Spec can carry, leaving out functions
+// and the state of a run.
+func spec(field reflect.StructField) bool {
+	return field.IsExported() && field.Type.Kind() != reflect.Func
+}
+
+func requireSet(t *testing.T, path string, v reflect.Value) {
+	t.Helper()
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !spec(field) {
+			continue
+		}
+		value := v.Field(i)
+		must.False(t, value.IsZero(), must.Sprintf("%s.%s is not set", path, field.Name))
+		if field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct {
+			requireSet(t, path+"."+field.Name, value.Elem())
+		}
+	}
+}
+
+func requireSame(t *testing.T, path string, exp, got reflect.Value) {
+	t.Helper()
+
+	switch exp.Kind() {
+	case reflect.Struct:
+		for i := range exp.NumField() {
+			field := exp.Type().Field(i)
+			if spec(field) {
+				requireSame(t, path+"."+field.Name, exp.Field(i), got.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		must.Eq(t, exp.IsNil(), got.IsNil(), must.Sprintf("%s is lost", path))
+		if !exp.IsNil() {
+			requireSame(t, path, exp.Elem(), got.Elem())
+		}
+	case reflect.Slice:
+		must.Eq(t, exp.Len(), got.Len(), must.Sprintf("%s is lost", path))
+		for i := range exp.Len() {
+			requireSame(t, fmt.Sprintf("%s[%d]", path, i), exp.Index(i), got.Index(i))
+		}
+	default:
+		must.True(t, reflect.DeepEqual(exp.Interface(), got.Interface()),
+			must.Sprintf("%s is %v, not %v, after loading", path, got.Interface(), exp.Interface()))
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// state is what a single run parses and uses, shared by the copies of the
+// components it resolves. The Component trees declared by users are never
+// modified, so they can be run any number of times.
+type state struct {
+	args cursor
+
+	// attached is whether the next argument is the value attached to the
+	// flag being parsed with "=", taken as is even if it starts with "-".
+	attached bool
+
+	// example is whether the arguments are those of an Example being
+	// verified, so the files and directories named by flags need not exist.
+	example bool
+
+	// unknown are the flags kept for PassThrough.
+	unknown []string
+
+	vals *values
+
+	// err is a problem with the arguments found by New, returned by parse.
+	err error
+
+	globals Flags
+	dups    Duplicates
+	version *VersionInfo
+	plugins string
+
+	aliases  map[string]string
+	expanded map[string]bool
+
+	authorize func(path []string) bool
+	onUnknown func(c *Component, name string, rest []string) Code
+
+	style    *style
+	cleanups *cleanups
+	profile  *profile
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	warn   io.Writer
+
+	getenv  func(string) string
+	logger  *slog.Logger
+	context context.Context
+}
+
+// retired holds, by the declared top of their tree, the values of the last
+// finished run of each tree. The next run of the tree clears and reuses them,
+// so repeated runs, such as in tests, shells, or servers, do not allocate
+// their maps again, while the results of a run stay readable until then.
+var retired sync.Map // map[*Component]*values
+
+// reclaim returns the values of the last finished run of the tree of top,
+// cleared, or new values if there are none to reuse.
+func reclaim(top *Component) *values {
+	if v, ok := retired.LoadAndDelete(top); ok {
+		vals := v.(*values)
+		vals.clear()
+		return vals
+	}
+	return new(values)
+}
+
+// retire keeps v for reuse by the next run of the tree of top.
+func (v *values) retire(top *Component) {
+	retired.Store(top, v)
+}
+
+// clear empties the maps of v, keeping them allocated.
+func (v *values) clear() {
+	clear(v.strings)
+	clear(v.ints)
+	clear(v.bools)
+	clear(v.durations)
+	clear(v.secrets)
+	clear(v.dates)
+	clear(v.bigs)
+	clear(v.bytes)
+	clear(v.files)
+	clear(v.dirs)
+	clear(v.globs)
+	clear(v.given)
+}
+
+// record appends vs to the values of identity in m, which is made on first
+// use, so a run only allocates the maps of the flag types it is given.
+func record[T any](m *map[string][]T, identity string, vs ...T) {
+	if *m == nil {
+		*m = make(map[string][]T)
+	}
+	(*m)[identity] = append((*m)[identity], vs...)
+}
+
+// change records that the flag with identity was given on the command line
+// once more.
+func (v *values) change(identity string) {
+	if v.given == nil {
+		v.given = make(map[string]int)
+	}
+	v.given[identity]++
+}
@@ -0,0 +1,88 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func benchmarkConfig() *Configuration {
+	return &Configuration{
+		Arguments: []string{"deploy", "--region", "us", "--wait", "5m", "--force", "app"},
+		Output:    io.Discard,
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{
+					Name: "deploy",
+					Flags: Flags{
+						{Type: StringFlag, Long: "region"},
+						{Type: DurationFlag, Long: "wait"},
+						{Type: BooleanFlag, Long: "force"},
+						{Type: IntFlag, Long: "replicas"},
+					},
+					Function: func(c *Component) Code {
+						_ = c.GetString("region")
+						return Success
+					},
+				},
+			},
+		},
+	}
+}
+
+func BenchmarkRunnable_Run(b *testing.B) {
+	config := benchmarkConfig()
+	b.ReportAllocs()
+	for range b.N {
+		if code := New(config).Run(); code != Success {
+			b.Fatalf("unexpected code %d", code)
+		}
+	}
+}
+
+func TestRunnable_Run_keepsValues(t *testing.T) {
+	t.Parallel()
+
+	config := benchmarkConfig()
+	r := New(config)
+	must.Eq(t, Success, r.Run())
+
+	leaf, err := r.Parse()
+	must.NoError(t, err)
+	must.Eq(t, "us", leaf.GetString("region"))
+	must.True(t, leaf.Changed("force"))
+	must.Eq(t, []string{"app"}, leaf.Arguments())
+}
+
+func TestNew_reclaimsValues(t *testing.T) {
+	t.Parallel()
+
+	config := benchmarkConfig()
+	first := New(config)
+	must.Eq(t, Success, first.Run())
+	vals := first.root.vals
+
+	// the values of the finished run are cleared and reused
+	second := New(config)
+	must.True(t, vals == second.root.vals)
+	must.MapEmpty(t, vals.strings)
+	must.MapEmpty(t, vals.given)
+
+	// those of a run not yet finished are not
+	third := New(config)
+	must.False(t, vals == third.root.vals)
+
+	must.Eq(t, Success, second.Run())
+	leaf, err := second.Parse()
+	must.NoError(t, err)
+	must.Eq(t, "us", leaf.GetString("region"))
+
+	// nor are those of another tree
+	other := New(benchmarkConfig())
+	must.False(t, vals == other.root.vals)
+}
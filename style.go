@@ -0,0 +1,151 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"slices"
+	"strings"
+)
+
+// Catalog translates the section headings, built-in messages, and help text
+// of babycli, keyed by their English text (e.g. "COMMANDS" or
+// "flag %q is not defined"). Text missing from the catalog is left as is.
+type Catalog map[string]string
+
+// SortOrder is the order in which commands and flags are listed in help.
+type SortOrder uint8
+
+const (
+	DeclarationOrder SortOrder = iota
+	AlphabeticalOrder
+)
+
+// GlobalsHelp is how global flags are shown in the help of subcommands.
+type GlobalsHelp uint8
+
+const (
+	// GlobalsListed lists the global flags in the help of every command.
+	GlobalsListed GlobalsHelp = iota
+
+	// GlobalsCollapsed lists the global flags in the help of the top
+	// command only, with a line pointing there in the help of subcommands.
+	GlobalsCollapsed
+
+	// GlobalsHidden lists the global flags in the help of the top command only.
+	GlobalsHidden
+)
+
+// HelpLayout controls the columns in which flags and commands are listed in
+// help. The zero value is the default layout.
+type HelpLayout struct {
+	// Gap adds spaces after the column of flag and command names.
+	Gap int
+
+	// MaxNameWidth limits the width of the column of flag and command names.
+	// Longer names are written on a line of their own, with the rest of their
+	// line below. A zero value is no limit.
+	MaxNameWidth int
+
+	// Truncate cuts help text longer than the terminal width, instead of
+	// wrapping it.
+	Truncate bool
+}
+
+// style holds the settings for formatting help and messages.
+type style struct {
+	width    int
+	messages Catalog
+	pager    bool
+	order    SortOrder
+	globals  GlobalsHelp
+	layout   HelpLayout
+}
+
+func (s *style) helpLayout() HelpLayout {
+	if s == nil {
+		return HelpLayout{}
+	}
+	return s.layout
+}
+
+// nameWidth returns the width of the column of names, the longest of names
+// within the MaxNameWidth of the layout.
+func (s *style) nameWidth(names []string) int {
+	limit := s.helpLayout().MaxNameWidth
+	width := 0
+	for _, name := range names {
+		if limit <= 0 || len(name) <= limit {
+			width = max(width, len(name))
+		}
+	}
+	return width
+}
+
+// writeName writes name padded to width and the Gap of the layout, on a line
+// of its own if it is wider, after which the line is indented to width.
+func (s *style) writeName(w io.Writer, name string, width int) {
+	if len(name) > width {
+		_, _ = io.WriteString(w, name)
+		_, _ = io.WriteString(w, "\n")
+		name = ""
+	}
+	_, _ = io.WriteString(w, rightPad(width+s.helpLayout().Gap, name))
+}
+
+// writeHelp writes the help text s at the column indent, wrapped or truncated
+// to the width of the terminal.
+func (s *style) writeHelp(w io.Writer, text string, indent int) {
+	if s.helpLayout().Truncate {
+		writeTruncated(w, text, indent, s.cols())
+		return
+	}
+	writeWrapped(w, text, indent, s.cols())
+}
+
+func (s *style) globalsHelp() GlobalsHelp {
+	if s == nil {
+		return GlobalsListed
+	}
+	return s.globals
+}
+
+func (s *style) cols() int {
+	if s == nil {
+		return 0
+	}
+	return s.width
+}
+
+func (s *style) text(key string) string {
+	if s == nil {
+		return key
+	}
+	if translated, exists := s.messages[key]; exists {
+		return translated
+	}
+	return key
+}
+
+func (s *style) components(cs Components) Components {
+	if s == nil || s.order != AlphabeticalOrder {
+		return cs
+	}
+	sorted := slices.Clone(cs)
+	slices.SortStableFunc(sorted, func(a, b *Component) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted
+}
+
+func (s *style) flags(fs Flags) Flags {
+	if s == nil || s.order != AlphabeticalOrder {
+		return fs
+	}
+	sorted := slices.Clone(fs)
+	slices.SortStableFunc(sorted, func(a, b *Flag) int {
+		return strings.Compare(a.Identity(), b.Identity())
+	})
+	return sorted
+}
@@ -0,0 +1,118 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// suggest returns the candidates close to name, such as those differing by a
+// typo or starting with name, closest first.
+func suggest(name string, candidates []string) []string {
+	limit := min(max(len(name)/3, 1), 2)
+
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == name {
+			continue
+		}
+		d := distance(name, candidate)
+		if d > limit && (len(name) < 2 || !strings.HasPrefix(candidate, name)) {
+			continue
+		}
+		matches = append(matches, match{name: candidate, distance: d})
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.distance - b.distance
+	})
+
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !slices.Contains(names, m.name) {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+// distance returns the number of insertions, deletions, substitutions, and
+// transpositions of adjacent characters turning a into b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// didYouMean lists the suggestions for a message, like `"a" or "b"`.
+func didYouMean(suggestions []string) string {
+	quoted := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		quoted = append(quoted, strconv.Quote(s))
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// Suggest returns up to max of the visible flags with a long name close to
+// name, such as differing by a typo, closest first, like "--verbose". A max
+// of zero or less is no limit.
+func (fs Flags) Suggest(name string, max int) []string {
+	var candidates []string
+	for _, f := range fs.visible() {
+		if len(name) > 1 && f.Long != "" {
+			candidates = append(candidates, f.Long)
+		}
+	}
+
+	suggestions := limit(suggest(name, candidates), max)
+	for i, s := range suggestions {
+		suggestions[i] = "--" + s
+	}
+	return suggestions
+}
+
+// Suggest returns the names of up to max of the visible components with a
+// name close to name, such as differing by a typo, closest first. A max of
+// zero or less is no limit.
+func (cs Components) Suggest(name string, max int) []string {
+	candidates := make([]string, 0, len(cs))
+	for _, c := range cs.visible() {
+		candidates = append(candidates, c.Name)
+	}
+	return limit(suggest(name, candidates), max)
+}
+
+func limit(suggestions []string, max int) []string {
+	if max > 0 && len(suggestions) > max {
+		return suggestions[:max]
+	}
+	return suggestions
+}
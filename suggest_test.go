@@ -0,0 +1,136 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func Test_suggest(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{"verbose", "version", "verify", "output", "quiet", "env"}
+
+	cases := []struct {
+		name string
+		exp  []string
+	}{
+		{name: "verbos", exp: []string{"verbose"}},
+		{name: "versoin", exp: []string{"version"}},
+		{name: "ver", exp: []string{"verify", "verbose", "version"}},
+		{name: "outptu", exp: []string{"output"}},
+		{name: "env", exp: []string{}},
+		{name: "enb", exp: []string{"env"}},
+		{name: "x", exp: []string{}},
+		{name: "deploy", exp: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			must.Eq(t, tc.exp, suggest(tc.name, candidates))
+		})
+	}
+}
+
+func Test_distance(t *testing.T) {
+	t.Parallel()
+
+	must.Eq(t, 0, distance("", ""))
+	must.Eq(t, 3, distance("", "abc"))
+	must.Eq(t, 1, distance("verbos", "verbose"))
+	must.Eq(t, 1, distance("versoin", "version"))
+	must.Eq(t, 1, distance("wiat", "wait"))
+	must.Eq(t, 3, distance("kitten", "sitting"))
+	must.Eq(t, 1, distance("héllo", "hello"))
+}
+
+func TestParse_suggestFlags(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		args   []string
+		expErr string
+	}{
+		{
+			args:   []string{"deploy", "--replica", "2"},
+			expErr: `babycli: flag "replica" is not defined, did you mean "--replicas"?`,
+		},
+		{
+			args:   []string{"--debg", "deploy"},
+			expErr: `babycli: flag "debg" is not defined, did you mean "--debug"?`,
+		},
+		{
+			args:   []string{"deploy", "--wiat=1s"},
+			expErr: `babycli: flag "wiat" is not defined, did you mean "--wait"?`,
+		},
+		{
+			args:   []string{"deploy", "--color"},
+			expErr: `babycli: flag "color" is not defined`,
+		},
+		{
+			args:   []string{"deploy", "-x"},
+			expErr: `babycli: flag "x" is not defined`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.args[1], func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(parseConfig(tc.args))
+			must.EqError(t, err, tc.expErr)
+			must.ErrorIs(t, err, ErrUnknownFlag)
+		})
+	}
+}
+
+func TestComponents_Suggest(t *testing.T) {
+	t.Parallel()
+
+	cs := Components{
+		{Name: "deploy"},
+		{Name: "delete"},
+		{Name: "describe"},
+		{Name: "debug", Hidden: true},
+		{Name: "status"},
+	}
+
+	cases := []struct {
+		name string
+		max  int
+		exp  []string
+	}{
+		{name: "depoly", exp: []string{"deploy"}},
+		{name: "de", exp: []string{"deploy", "delete", "describe"}},
+		{name: "de", max: 2, exp: []string{"deploy", "delete"}},
+		{name: "debg", exp: []string{}},
+		{name: "statsu", max: 1, exp: []string{"status"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			must.Eq(t, tc.exp, cs.Suggest(tc.name, tc.max))
+		})
+	}
+}
+
+func TestFlags_Suggest(t *testing.T) {
+	t.Parallel()
+
+	fs := Flags{
+		{Type: BooleanFlag, Long: "verbose", Short: "v"},
+		{Type: StringFlag, Long: "version"},
+		{Type: BooleanFlag, Long: "verify", Hidden: true},
+		{Type: StringFlag, Short: "o"},
+	}
+
+	must.Eq(t, []string{"--verbose", "--version"}, fs.Suggest("ver", 0))
+	must.Eq(t, []string{"--verbose"}, fs.Suggest("ver", 1))
+	must.Eq(t, []string{"--version"}, fs.Suggest("versoin", 3))
+	must.Eq(t, []string{}, fs.Suggest("o", 0))
+}
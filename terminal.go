@@ -0,0 +1,76 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// terminalWidth returns the width of the terminal behind output, preferring
+// the COLUMNS environment variable, or 0 if the width cannot be detected.
+func terminalWidth(output io.Writer, getenv func(string) string) int {
+	if columns, err := strconv.Atoi(getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if f, ok := output.(*os.File); ok {
+		if width, _, ok := terminalSize(f); ok {
+			return width
+		}
+	}
+	return 0
+}
+
+// wrap splits s into lines no longer than width, breaking between words.
+// A width of zero or less disables wrapping.
+func wrap(s string, width int) []string {
+	if width <= 0 || len(s) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	line := new(strings.Builder)
+	for _, word := range strings.Fields(s) {
+		if line.Len() > 0 && line.Len()+1+len(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString(" ")
+		}
+		line.WriteString(word)
+	}
+	return append(lines, line.String())
+}
+
+// writeTruncated writes the first line of s, cut with "..." to fit within
+// width.
+func writeTruncated(w io.Writer, s string, indent, width int) {
+	line, _, _ := strings.Cut(s, "\n")
+	if width > 0 {
+		available := max(width-indent, 20)
+		if runes := []rune(line); len(runes) > available {
+			line = string(runes[:available-3]) + "..."
+		}
+	}
+	_, _ = io.WriteString(w, line)
+}
+
+// writeWrapped writes s wrapped to fit within width, with the continuation
+// lines indented to the given column.
+func writeWrapped(w io.Writer, s string, indent, width int) {
+	available := 0
+	if width > 0 {
+		available = max(width-indent, 20)
+	}
+	for i, line := range wrap(s, available) {
+		if i > 0 {
+			_, _ = io.WriteString(w, "\n")
+			_, _ = io.WriteString(w, strings.Repeat(" ", indent))
+		}
+		_, _ = io.WriteString(w, line)
+	}
+}
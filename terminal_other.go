@@ -0,0 +1,14 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package babycli
+
+import (
+	"os"
+)
+
+func terminalSize(*os.File) (int, int, bool) {
+	return 0, 0, false
+}
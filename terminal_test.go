@@ -0,0 +1,84 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		s     string
+		width int
+		exp   []string
+	}{
+		{
+			name:  "disabled",
+			s:     "the quick brown fox",
+			width: 0,
+			exp:   []string{"the quick brown fox"},
+		},
+		{
+			name:  "fits",
+			s:     "the quick brown fox",
+			width: 19,
+			exp:   []string{"the quick brown fox"},
+		},
+		{
+			name:  "wrapped",
+			s:     "the quick brown fox jumps over the lazy dog",
+			width: 10,
+			exp:   []string{"the quick", "brown fox", "jumps over", "the lazy", "dog"},
+		},
+		{
+			name:  "long word",
+			s:     "a supercalifragilistic word",
+			width: 5,
+			exp:   []string{"a", "supercalifragilistic", "word"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := wrap(tc.s, tc.width)
+			must.Eq(t, tc.exp, result)
+		})
+	}
+}
+
+func TestHelp_wrapped(t *testing.T) {
+	t.Parallel()
+
+	w := new(strings.Builder)
+	config := &Configuration{
+		Arguments: []string{"--help"},
+		Output:    w,
+		Width:     40,
+		Top: &Component{
+			Name:        "tool",
+			Description: "A tool with a description long enough that it must be wrapped.",
+			Flags: Flags{
+				{
+					Type: StringFlag,
+					Long: "name",
+					Help: "the name of the thing to be operated upon",
+				},
+			},
+		},
+	}
+
+	c := New(config)
+	result := c.Run()
+	must.Eq(t, Success, result)
+
+	text := w.String()
+	must.StrContains(t, text, "DESCRIPTION:\n  A tool with a description long enough\n  that it must be wrapped.\n")
+	must.StrContains(t, text, "--name   string - the name of the thing\n                  to be operated upon\n")
+}
@@ -0,0 +1,33 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package babycli
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	rows    uint16
+	columns uint16
+	x       uint16
+	y       uint16
+}
+
+func terminalSize(f *os.File) (int, int, bool) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		f.Fd(),
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(&ws)),
+	)
+	if errno != 0 || ws.columns == 0 {
+		return 0, 0, false
+	}
+	return int(ws.columns), int(ws.rows), true
+}
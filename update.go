@@ -0,0 +1,191 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UpdateChecker looks up the newest released version of a program, for
+// example from a release API. It is supplied by the application, so babycli
+// makes no network requests of its own.
+type UpdateChecker interface {
+	LatestVersion(ctx context.Context) (string, error)
+}
+
+// Updates configures checking for a newer release of the program after a
+// command completes, printing a notice to WarnOutput if one is found.
+type Updates struct {
+	Checker UpdateChecker
+
+	// Interval is the minimum time between checks, whether they succeed or
+	// not (default 24 hours).
+	Interval time.Duration
+
+	// Timeout bounds how long a check may delay the exit of the program
+	// (default 2 seconds).
+	Timeout time.Duration
+
+	// StateFile records when the last check happened (default "update-check"
+	// in a directory named after the program, in the user cache directory).
+	StateFile string
+}
+
+const (
+	defaultUpdateInterval = 24 * time.Hour
+	defaultUpdateTimeout  = 2 * time.Second
+)
+
+func (u *Updates) interval() time.Duration {
+	if u.Interval <= 0 {
+		return defaultUpdateInterval
+	}
+	return u.Interval
+}
+
+func (u *Updates) timeout() time.Duration {
+	if u.Timeout <= 0 {
+		return defaultUpdateTimeout
+	}
+	return u.Timeout
+}
+
+func (u *Updates) stateFile(name string) (string, error) {
+	if u.StateFile != "" {
+		return u.StateFile, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name, "update-check"), nil
+}
+
+// checkUpdates prints a notice if the Checker reports a version newer than
+// the version of the program, at most once per Interval.
+func (r *Runnable) checkUpdates() {
+	u := r.updates
+	if u == nil || u.Checker == nil || r.root.version == nil || r.root.version.Version == "" {
+		return
+	}
+
+	name := program(r.root)
+	path, err := u.stateFile(name)
+	if err != nil {
+		r.root.logger.Debug("babycli: unable to check for updates", "error", err)
+		return
+	}
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < u.interval() {
+		return
+	}
+
+	// The check is recorded before it is made, so a failing one, such as
+	// while offline, is not retried before the Interval either.
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+		err = os.WriteFile(path, nil, 0o644)
+	}
+	if err != nil {
+		r.root.logger.Debug("babycli: unable to record update check", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.root.context, u.timeout())
+	defer cancel()
+
+	latest, err := u.Checker.LatestVersion(ctx)
+	if err != nil {
+		r.root.logger.Debug("babycli: unable to check for updates", "error", err)
+		return
+	}
+
+	current := r.root.version.Version
+	if newer(latest, current) {
+		writef(r.root.warn, r.root.style.text("a new version of %s is available: %s (current %s)"), name, latest, current)
+	}
+}
+
+// newer returns whether version a is newer than version b, comparing their
+// dotted numbers, then their pre-releases like semver. Versions which are not
+// numbered, such as "dev", are never newer nor outdated.
+func newer(a, b string) bool {
+	na, pa, okA := semver(a)
+	nb, pb, okB := semver(b)
+	if !okA || !okB {
+		return false
+	}
+
+	for i := 0; i < max(len(na), len(nb)); i++ {
+		var x, y int
+		if i < len(na) {
+			x = na[i]
+		}
+		if i < len(nb) {
+			y = nb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+
+	switch {
+	case pa == pb:
+		return false
+	case pa == "":
+		return true
+	case pb == "":
+		return false
+	default:
+		return prerelease(pa, pb) > 0
+	}
+}
+
+// prerelease compares the pre-releases a and b by their dot separated
+// identifiers, numeric ones by value and lower than alphanumeric ones, with a
+// shorter list of otherwise equal identifiers lower.
+func prerelease(a, b string) int {
+	ia := strings.Split(a, ".")
+	ib := strings.Split(b, ".")
+	for i := range min(len(ia), len(ib)) {
+		x, errX := strconv.Atoi(ia[i])
+		y, errY := strconv.Atoi(ib[i])
+		switch {
+		case errX == nil && errY == nil:
+			if x != y {
+				return cmp.Compare(x, y)
+			}
+		case errX == nil:
+			return -1
+		case errY == nil:
+			return 1
+		case ia[i] != ib[i]:
+			return strings.Compare(ia[i], ib[i])
+		}
+	}
+	return cmp.Compare(len(ia), len(ib))
+}
+
+// semver splits a version like "v1.2.3-rc.1+meta" into its numbers and
+// pre-release.
+func semver(s string) ([]int, string, bool) {
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, pre, true
+}
@@ -0,0 +1,153 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+type releases struct {
+	version string
+	err     error
+	block   bool
+	calls   int
+}
+
+func (l *releases) LatestVersion(ctx context.Context) (string, error) {
+	l.calls++
+	if l.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return l.version, l.err
+}
+
+func TestRun_updates(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		latest    *releases
+		checked   time.Duration
+		expCalls  int
+		expStderr string
+	}{
+		{
+			name:      "newer",
+			latest:    &releases{version: "v1.3.0"},
+			expCalls:  1,
+			expStderr: "a new version of tool is available: v1.3.0 (current v1.2.3)\n",
+		},
+		{
+			name:     "same",
+			latest:   &releases{version: "v1.2.3"},
+			expCalls: 1,
+		},
+		{
+			name:     "failed",
+			latest:   &releases{err: errors.New("offline")},
+			expCalls: 1,
+		},
+		{
+			name:     "timeout",
+			latest:   &releases{version: "v1.3.0", block: true},
+			expCalls: 1,
+		},
+		{
+			name:     "checked recently",
+			latest:   &releases{version: "v1.3.0"},
+			checked:  time.Hour,
+			expCalls: 0,
+		},
+		{
+			name:      "checked long ago",
+			latest:    &releases{version: "v1.3.0"},
+			checked:   25 * time.Hour,
+			expCalls:  1,
+			expStderr: "a new version of tool is available: v1.3.0 (current v1.2.3)\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "tool", "update-check")
+			if tc.checked > 0 {
+				must.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				must.NoError(t, os.WriteFile(path, []byte("v1.2.3\n"), 0o644))
+				when := time.Now().Add(-tc.checked)
+				must.NoError(t, os.Chtimes(path, when, when))
+			}
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			code := New(&Configuration{
+				Top: &Component{
+					Name: "tool",
+					Function: func(c *Component) Code {
+						write(c.Stdout(), "done")
+						return Success
+					},
+				},
+				Version:    "v1.2.3",
+				Updates:    &Updates{Checker: tc.latest, StateFile: path, Timeout: 10 * time.Millisecond},
+				Stdout:     stdout,
+				WarnOutput: stderr,
+			}).Run()
+			must.Eq(t, Success, code)
+			must.Eq(t, "done\n", stdout.String())
+			must.Eq(t, tc.expStderr, stderr.String())
+			must.Eq(t, tc.expCalls, tc.latest.calls)
+
+			info, err := os.Stat(path)
+			must.NoError(t, err)
+			if tc.checked == 0 || tc.checked > 24*time.Hour {
+				must.Less(t, time.Minute, time.Since(info.ModTime()))
+			}
+		})
+	}
+}
+
+func Test_newer(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		a, b string
+		exp  bool
+	}{
+		{a: "v1.2.4", b: "v1.2.3", exp: true},
+		{a: "1.10.0", b: "v1.9.9", exp: true},
+		{a: "v1.2.3", b: "v1.2.3", exp: false},
+		{a: "v1.2.3", b: "v1.3.0", exp: false},
+		{a: "v1.2", b: "v1.2.0", exp: false},
+		{a: "v1.2.3", b: "v1.2.3-rc.1", exp: true},
+		{a: "v1.2.3-rc.1", b: "v1.2.3", exp: false},
+		{a: "v1.2.3-rc.2", b: "v1.2.3-rc.1", exp: true},
+		{a: "v1.2.3-rc.10", b: "v1.2.3-rc.9", exp: true},
+		{a: "v1.2.3-rc.9", b: "v1.2.3-rc.10", exp: false},
+		{a: "v1.2.3-rc.1.1", b: "v1.2.3-rc.1", exp: true},
+		{a: "v1.2.3-beta", b: "v1.2.3-2", exp: true},
+		{a: "v1.2.3-beta", b: "v1.2.3-alpha", exp: true},
+		{a: "v1.2.3+build", b: "v1.2.3", exp: false},
+		{a: "v1.3.0", b: "dev", exp: false},
+		{a: "dev", b: "v1.2.3", exp: false},
+		{a: "nightly-2", b: "nightly-1", exp: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			t.Parallel()
+			must.Eq(t, tc.exp, newer(tc.a, tc.b))
+		})
+	}
+}
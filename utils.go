@@ -11,7 +11,7 @@ import (
 func panicf(msg string, args ...any) {
 	s := fmt.Sprintf(msg, args...)
 	s = "babycli: " + s
-	panic(s)
+	panic(&misuseError{msg: s})
 }
 
 func write(output io.Writer, msg string) {
@@ -4,21 +4,44 @@
 package babycli
 
 import (
-	"io"
+	"errors"
+	"fmt"
 	"slices"
 )
 
-func (c *Component) validate(output io.Writer) bool {
-	ok := true
+func (c *Component) validate() error {
+	var errs []error
 
-	for _, f := range c.Flags {
+	for _, f := range c.options() {
 		if len(f.Long) == 1 {
-			writef(output, "babycli: long flag %q must be more than one character", f.Long)
-			ok = false
+			errs = append(errs, fmt.Errorf("babycli: long flag %q must be more than one character", f.Long))
 		}
 		if len(f.Short) > 1 {
-			writef(output, "babycli: short flag %q must be one character", f.Short)
-			ok = false
+			errs = append(errs, fmt.Errorf("babycli: short flag %q must be one character", f.Short))
+		}
+		if !f.Repeats && max(f.MinOccurrences, f.MaxOccurrences) > 1 {
+			errs = append(errs, fmt.Errorf("babycli: flag %q must repeat to be given more than once", f.Identity()))
+		}
+		if f.Separator != "" && !f.Repeats {
+			errs = append(errs, fmt.Errorf("babycli: flag %q must repeat to have a Separator", f.Identity()))
+		}
+		if f.Separator != "" && !slices.Contains([]FlagType{StringFlag, IntFlag, DurationFlag}, f.Type) {
+			errs = append(errs, fmt.Errorf("babycli: %s flag %q cannot have a Separator", f.Type, f.Identity()))
+		}
+		if f.Encoding != Base64 && f.Type != BytesLiteralFlag {
+			errs = append(errs, fmt.Errorf("babycli: %s flag %q cannot have an Encoding", f.Type, f.Identity()))
+		}
+		if f.Verbosity && (f.Type != BooleanFlag || !f.Repeats) {
+			errs = append(errs, fmt.Errorf("babycli: verbosity flag %q must be a repeating boolean flag", f.Identity()))
+		}
+		if f.Default != nil && f.Default.Value != nil && f.Default.Func != nil {
+			errs = append(errs, fmt.Errorf("babycli: flag %q sets both Default Value and Func", f.Identity()))
+		}
+		if f.Default != nil && f.Default.Func == nil && !f.Type.holds(f.Default.Value) {
+			errs = append(errs, fmt.Errorf("babycli: default of %s flag %q has type %T", f.Type, f.Identity(), f.Default.Value))
+		}
+		if f.MaxOccurrences > 0 && f.MaxOccurrences < f.MinOccurrences {
+			errs = append(errs, fmt.Errorf("babycli: flag %q has MaxOccurrences below MinOccurrences", f.Identity()))
 		}
 	}
 
@@ -26,21 +49,30 @@ func (c *Component) validate(output io.Writer) bool {
 
 	for _, cmd := range c.Components {
 		if slices.Contains(names, cmd.Name) {
-			writef(output, "babycli: component %q set twice", cmd.Name)
-			ok = false
+			errs = append(errs, fmt.Errorf("babycli: component %q set twice", cmd.Name))
 		} else {
 			names = append(names, cmd.Name)
 		}
 
 		switch len(cmd.Name) {
 		case 0:
-			writef(output, "babycli: component name missing")
-			ok = false
+			errs = append(errs, errors.New("babycli: component name missing"))
 		case 1:
-			writef(output, "babycli: component %q must be more than one character", cmd.Name)
-			ok = false
+			errs = append(errs, fmt.Errorf("babycli: component %q must be more than one character", cmd.Name))
 		}
 	}
 
-	return ok
+	if c.Function != nil && c.FunctionE != nil {
+		errs = append(errs, fmt.Errorf("babycli: component %q sets both Function and FunctionE", c.Name))
+	}
+
+	if c.RunWithoutSubcommand && c.Default != "" {
+		errs = append(errs, fmt.Errorf("babycli: component %q sets both RunWithoutSubcommand and Default", c.Name))
+	}
+
+	if c.Default != "" && !c.Components.Contains(c.Default) {
+		errs = append(errs, fmt.Errorf("babycli: default component %q is not defined", c.Default))
+	}
+
+	return errors.Join(errs...)
 }
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/shoenig/test/must"
 )
@@ -136,3 +137,139 @@ func TestComponent_validate_duplicate_commands(t *testing.T) {
 	message := strings.TrimSpace(w.String())
 	must.Eq(t, `babycli: component "first" set twice`, message)
 }
+
+func TestComponent_validate_default_missing(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Default: "second",
+			Components: Components{
+				{
+					Name: "first",
+				},
+			},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	message := strings.TrimSpace(w.String())
+	must.Eq(t, `babycli: default component "second" is not defined`, message)
+}
+
+func TestComponent_validate_function_twice(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Name:      "program",
+			Function:  func(*Component) Code { return Success },
+			FunctionE: func(*Component) error { return nil },
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	message := strings.TrimSpace(w.String())
+	must.Eq(t, `babycli: component "program" sets both Function and FunctionE`, message)
+}
+
+func TestComponent_validate_occurrences(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Flags: Flags{
+				{Type: StringFlag, Long: "file", MaxOccurrences: 3},
+				{Type: StringFlag, Long: "tag", Repeats: true, MinOccurrences: 2, MaxOccurrences: 1},
+			},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	message := strings.TrimSpace(w.String())
+	must.Eq(t, `babycli: flag "file" must repeat to be given more than once
+babycli: flag "tag" has MaxOccurrences below MinOccurrences`, message)
+}
+
+func TestComponent_validate_separator(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Flags: Flags{
+				{Type: IntFlag, Long: "port", Separator: ","},
+				{Type: BooleanFlag, Long: "force", Repeats: true, Separator: ","},
+			},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	message := strings.TrimSpace(w.String())
+	must.Eq(t, `babycli: flag "port" must repeat to have a Separator
+babycli: boolean flag "force" cannot have a Separator`, message)
+}
+
+func TestComponent_validate_encoding(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Flags: Flags{
+				{Type: StringFlag, Long: "key", Encoding: Hex},
+				{Type: BytesLiteralFlag, Long: "nonce", Encoding: Hex},
+			},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	must.Eq(t, `babycli: string flag "key" cannot have an Encoding`, strings.TrimSpace(w.String()))
+}
+
+func TestComponent_validate_default_type(t *testing.T) {
+	t.Parallel()
+
+	config := &Configuration{
+		Top: &Component{
+			Flags: Flags{
+				{Type: DurationFlag, Long: "wait", Default: &Default{Value: 30}},
+				{Type: IntFlag, Long: "port", Default: &Default{Value: 8080}},
+				{Type: StringFlag, Long: "token", Default: &Default{Hidden: true}},
+				{Type: DateFlag, Long: "since", Default: &Default{Func: func() any { return time.Now() }}},
+			},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	c := New(config)
+	c.output = w
+
+	result := c.Run()
+	must.One(t, result)
+	must.Eq(t, `babycli: default of duration flag "wait" has type int
+babycli: default of string flag "token" has type <nil>`, strings.TrimSpace(w.String()))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"slices"
+	"strings"
+)
+
+// VersionInfo describes the build of a program.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// BuildVersion returns the VersionInfo recorded in the running binary by the
+// go command: the module version, VCS commit and time, and Go version. A
+// non-empty version replaces the module version.
+func BuildVersion(version string) *VersionInfo {
+	v := &VersionInfo{Version: version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	if v.Version == "" && info.Main.Version != "(devel)" {
+		v.Version = info.Main.Version
+	}
+	v.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Commit = setting.Value
+		case "vcs.time":
+			v.Date = setting.Value
+		}
+	}
+	return v
+}
+
+// String returns the version followed by the other details known.
+func (v *VersionInfo) String() string {
+	var details []string
+	if v.Commit != "" {
+		details = append(details, "commit "+v.Commit)
+	}
+	if v.Date != "" {
+		details = append(details, "built "+v.Date)
+	}
+	if v.GoVersion != "" {
+		details = append(details, v.GoVersion)
+	}
+
+	version := v.Version
+	if version == "" {
+		version = "unknown"
+	}
+	if len(details) == 0 {
+		return version
+	}
+	return version + " (" + strings.Join(details, ", ") + ")"
+}
+
+var versionFlag = &Flag{
+	Type: BooleanFlag,
+	Long: "version",
+	Help: "print version information",
+}
+
+// versionSet returns whether the built-in --version flag was given.
+func (c *Component) versionSet() bool {
+	return c.state != nil &&
+		slices.Contains(c.globals, versionFlag) &&
+		slices.Contains(c.vals.bools[versionFlag.Long], true)
+}
+
+// newVersionComponent creates the built-in version command.
+func newVersionComponent() *Component {
+	return &Component{
+		Name: "version",
+		Help: "print version information",
+		Flags: Flags{
+			{
+				Type: BooleanFlag,
+				Long: "json",
+				Help: "print version information as JSON",
+			},
+		},
+		FunctionE: printVersion,
+	}
+}
+
+func printVersion(c *Component) error {
+	if !c.GetBool("json") {
+		write(c.stdout, c.version.String())
+		return nil
+	}
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c.version)
+}
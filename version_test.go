@@ -0,0 +1,149 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestVersionInfo_String(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		info VersionInfo
+		exp  string
+	}{
+		{name: "empty", info: VersionInfo{}, exp: "unknown"},
+		{name: "version", info: VersionInfo{Version: "v1.2.3"}, exp: "v1.2.3"},
+		{
+			name: "full",
+			info: VersionInfo{Version: "v1.2.3", Commit: "abc123", Date: "2024-05-01T10:00:00Z", GoVersion: "go1.23.0"},
+			exp:  "v1.2.3 (commit abc123, built 2024-05-01T10:00:00Z, go1.23.0)",
+		},
+		{
+			name: "partial",
+			info: VersionInfo{Commit: "abc123"},
+			exp:  "unknown (commit abc123)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			must.Eq(t, tc.exp, tc.info.String())
+		})
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	t.Parallel()
+
+	info := BuildVersion("v2.0.0")
+	must.Eq(t, "v2.0.0", info.Version)
+	must.Eq(t, runtime.Version(), info.GoVersion)
+}
+
+func TestRun_version(t *testing.T) {
+	t.Parallel()
+
+	info := &VersionInfo{Version: "v1.2.3", Commit: "abc123", GoVersion: "go1.23.0"}
+
+	cases := []struct {
+		name   string
+		args   []string
+		top    *Component
+		expOut string
+	}{
+		{
+			name:   "flag",
+			args:   []string{"--version"},
+			expOut: "v1.2.3 (commit abc123, go1.23.0)\n",
+		},
+		{
+			name:   "flag of subcommand",
+			args:   []string{"deploy", "--version"},
+			expOut: "v1.2.3 (commit abc123, go1.23.0)\n",
+		},
+		{
+			name:   "command",
+			args:   []string{"version"},
+			expOut: "v1.2.3 (commit abc123, go1.23.0)\n",
+		},
+		{
+			name: "json",
+			args: []string{"version", "--json"},
+			expOut: `{
+  "version": "v1.2.3",
+  "commit": "abc123",
+  "go_version": "go1.23.0"
+}
+`,
+		},
+		{
+			name: "leaf",
+			args: []string{"--version"},
+			top: &Component{
+				Name:     "tool",
+				Function: func(*Component) Code { return Failure },
+			},
+			expOut: "v1.2.3 (commit abc123, go1.23.0)\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			top := tc.top
+			if top == nil {
+				top = &Component{
+					Name: "tool",
+					Components: Components{
+						{
+							Name:     "deploy",
+							Flags:    Flags{{Type: StringFlag, Long: "env", Require: true}},
+							Function: func(*Component) Code { return Failure },
+						},
+					},
+				}
+			}
+
+			out := new(strings.Builder)
+			code := New(&Configuration{
+				Arguments:   tc.args,
+				Top:         top,
+				VersionInfo: info,
+				Output:      out,
+			}).Run()
+			must.Eq(t, Success, code)
+			must.Eq(t, tc.expOut, out.String())
+		})
+	}
+}
+
+func TestRun_versionHelp(t *testing.T) {
+	t.Parallel()
+
+	out := new(strings.Builder)
+	code := New(&Configuration{
+		Arguments:   []string{"--help"},
+		VersionInfo: &VersionInfo{Version: "v1.2.3", Date: "2024-05-01"},
+		Output:      out,
+		Top: &Component{
+			Name: "tool",
+			Components: Components{
+				{Name: "deploy", Function: func(*Component) Code { return Success }},
+			},
+		},
+	}).Run()
+	must.Eq(t, Success, code)
+	must.StrContains(t, out.String(), "VERSION:\n  v1.2.3 (built 2024-05-01)\n")
+	must.StrContains(t, out.String(), "version - print version information\n")
+	must.StrContains(t, out.String(), "--version   boolean - print version information\n")
+}
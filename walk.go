@@ -0,0 +1,37 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+// Walk calls fn for c and each of its descendants in depth-first order, with
+// the names of the components leading to each one. Returning false from fn
+// skips the descendants of that component.
+func (c *Component) Walk(fn func(path []string, c *Component) bool) {
+	var path []string
+	if c.Name != "" {
+		path = []string{c.Name}
+	}
+	c.walk(path, fn)
+}
+
+func (c *Component) walk(path []string, fn func([]string, *Component) bool) {
+	if !fn(path, c) {
+		return
+	}
+	for _, cmd := range c.Components {
+		cmd.walk(append(path[:len(path):len(path)], cmd.Name), fn)
+	}
+}
+
+// Find returns the descendant of c reached by the path of subcommand names, or
+// nil if there is none. An empty path returns c.
+func (c *Component) Find(path ...string) *Component {
+	target := c
+	for _, name := range path {
+		if !target.Components.Contains(name) {
+			return nil
+		}
+		target = target.Components.Get(name)
+	}
+	return target
+}
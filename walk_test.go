@@ -0,0 +1,64 @@
+// Copyright (c) The Noxide Project Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package babycli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestComponent_Walk(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Components: Components{
+			{
+				Name: "db",
+				Components: Components{
+					{Name: "migrate"},
+					{Name: "seed"},
+				},
+			},
+			{
+				Name: "debug",
+				Components: Components{
+					{Name: "trace"},
+				},
+			},
+		},
+	}
+
+	var visited []string
+	top.Walk(func(path []string, c *Component) bool {
+		visited = append(visited, strings.Join(path, " "))
+		return c.Name != "debug"
+	})
+
+	must.Eq(t, []string{
+		"tool",
+		"tool db",
+		"tool db migrate",
+		"tool db seed",
+		"tool debug",
+	}, visited)
+}
+
+func TestComponent_Find(t *testing.T) {
+	t.Parallel()
+
+	top := &Component{
+		Name: "tool",
+		Components: Components{
+			{Name: "remote", Components: Components{{Name: "add"}}},
+		},
+	}
+
+	must.Eq(t, top, top.Find())
+	must.Eq(t, "add", top.Find("remote", "add").Name)
+	must.Nil(t, top.Find("remote", "remove"))
+	must.Nil(t, top.Find("missing", "add"))
+}